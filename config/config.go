@@ -2,18 +2,32 @@ package config
 
 import (
 	"github.com/BurntSushi/toml"
+	"os"
+	"path/filepath"
 	"sync"
 )
 
 type Config struct {
-	App     app
-	Mysql   mysql
-	Mongodb mongodb
-	Redis   redis
+	App      app
+	Mysql    mysql
+	Mongodb  mongodb
+	Redis    redis
+	Session  session
+	Rbac     rbac
+	Login    login
+	Totp     totp
+	Cron     cron
+	View     view
+	Rank     rank
+	Cache    cache
+	Sanitize sanitize
+	Article  article
+	Trash    trash
 }
 
 type app struct {
-	Addr string
+	Addr    string
+	Proxies []string //受信任的反向代理ip或cidr，只有来自这些地址的请求才使用X-Forwarded-For
 }
 
 type mysql struct {
@@ -33,6 +47,67 @@ type redis struct {
 	MaxActive int
 }
 
+type session struct {
+	Secret string //token签名密钥
+	Expire int    //token有效期，单位秒
+}
+
+type login struct {
+	MaxFail   int //同一用户名在window内允许的最大失败次数
+	MaxIpFail int //同一ip在window内允许的最大失败次数
+	Window    int //失败次数统计窗口，单位秒
+	Lockout   int //锁定时长，单位秒
+	Delay     int //失败后的基础延迟，单位毫秒，每多失败一次翻倍
+	MaxDelay  int //最大延迟，单位毫秒
+}
+
+type totp struct {
+	Issuer string //身份验证器中显示的名称
+}
+
+type cron struct {
+	Interval int //定时任务的执行间隔，单位秒
+}
+
+type view struct {
+	Window int //同一访客重复访问不计数的时间，单位秒
+}
+
+type rank struct {
+	Kinds   []string //开启的排行榜类型，为空时全部开启，见logic.RankToday等
+	Days    int      //本周热门统计的天数
+	Decay   float64  //本周热门中每早一天浏览数的权重衰减，如0.8表示昨天的浏览按80%计算
+	Cache   int      //本周热门合并结果的缓存时间，单位秒
+	Rebuild int      //从数据库重建排行榜的间隔，单位秒，今日和本周热门只重建redis中丢失的日期
+}
+
+type cache struct {
+	Article int //单篇文章的缓存时间，单位秒
+	List    int //文章列表的缓存时间，单位秒
+}
+
+type sanitize struct {
+	Elements []string //文章在默认规则之外允许的标签
+	Attrs    []string //文章在默认规则之外允许的属性，对所有标签生效
+	Style    bool     //是否保留文章的内联样式，关闭时删除所有style属性
+	Comment  []string //评论允许的标签，为空时去掉所有标签
+}
+
+type article struct {
+	AutoBrief   bool //摘要为空时是否从内容自动提取
+	BriefLength int  //自动摘要的最大字数
+	CjkSpeed    int  //每分钟阅读的中文字数，用于计算阅读时间
+	WordSpeed   int  //每分钟阅读的英文单词数
+}
+
+type trash struct {
+	Retention int //回收站中文章的保留天数，超过后自动彻底删除，0表示不自动删除
+}
+
+type rbac struct {
+	Protected []int //受保护的管理员id，不能删除也不能修改角色
+}
+
 var (
 	c    *Config
 	once sync.Once
@@ -44,9 +119,28 @@ var (
  */
 func New() *Config {
 	once.Do(func() { //只执行一次
-		if _, err := toml.DecodeFile("config.toml", &c); err != nil {
+		if _, err := toml.DecodeFile(file(), &c); err != nil {
 			panic(err.Error())
 		}
 	})
 	return c
 }
+
+//配置文件路径，当前目录没有时向上级目录查找，在子目录中运行go test时也能读到
+func file() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "config.toml"
+	}
+	for {
+		path := filepath.Join(dir, "config.toml")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "config.toml"
+		}
+		dir = parent
+	}
+}
@@ -13,7 +13,7 @@ import (
  * @apiName 获取文章信息by path
  * @apiGroup article
  * @apiVersion 1.0.0
- * @apiDescription 用于后台管理员获取文章信息
+ * @apiDescription 获取文章信息，未登录时只能获取已发布且在发布时间内的文章，带上管理员token可以获取授权节点下的任意文章
  * @apiSampleRequest /article/:id
  * @apiParam {int} id文章id
  * @apiSuccess {bool} state 状态
@@ -21,7 +21,66 @@ import (
  */
 func ArticleGet(ctx *iris.Context) {
 	id := Tools.ParseInt(ctx.Param("id"), 0)
-	ctx.JSON(iris.StatusOK, model.ArticleGet(id))
+	if user, ok := logic.SessionCheck(getToken(ctx)); ok {
+		ctx.JSON(iris.StatusOK, logic.ArticleGet(id, user))
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.ArticleGetPublic(id))
+}
+
+/**
+ * @api {get} /slug/:slug get article by slug
+ * @apiName 根据slug获取文章信息
+ * @apiGroup article
+ * @apiVersion 1.0.0
+ * @apiDescription 根据固定链接获取文章，权限同/article/:id。使用修改前的slug时返回301，
+ * Location为新的地址，msg中包含文章信息
+ * @apiSampleRequest /slug/:slug
+ * @apiParam {string} slug 文章slug
+ * @apiSuccess {bool} state 状态
+ * @apiSuccess {String} msg 消息
+ */
+func ArticleGetBySlug(ctx *iris.Context) {
+	slug := ctx.Param("slug")
+	if err := validate.Var(slug, "required,max=100"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	user, admin := logic.SessionCheck(getToken(ctx))
+	result, redirect := logic.ArticleGetBySlug(slug, !admin, user)
+	if redirect != "" {
+		ctx.SetHeader("Location", "/slug/"+redirect)
+		ctx.JSON(iris.StatusMovedPermanently, result)
+		return
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+ * @api {post} /article/view view article
+ * @apiName 记录文章浏览
+ * @apiGroup article
+ * @apiVersion 1.0.0
+ * @apiDescription 记录一次浏览并返回浏览数，同一访客在一段时间内重复访问不计数。浏览数每隔一段时间写入文章的count字段
+ * @apiSampleRequest /article/view
+ * @apiParam {int} id 文章id
+ * @apiParam {string} visitor 访客标识，如前端生成的id，可选，默认使用ip和user agent
+ * @apiSuccess {bool} state 状态
+ * @apiSuccess {int} msg 浏览数
+ */
+func ArticleView(ctx *iris.Context) {
+	id := Tools.ParseInt(ctx.FormValueString("id"), 0)
+	visitor := ctx.FormValueString("visitor")
+	err1 := validate.Var(id, "required,min=1")
+	err2 := validate.Var(visitor, "max=64")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	if visitor == "" {
+		visitor = clientIp(ctx) + "|" + ctx.RequestHeader("User-Agent")
+	}
+	ctx.JSON(iris.StatusOK, logic.ArticleView(id, visitor))
 }
 
 /**
@@ -29,8 +88,13 @@ func ArticleGet(ctx *iris.Context) {
 * @apiName 更新article信息
 * @apiGroup article
 * @apiVersion 1.0.0
-* @apiDescription 后台管理员更新文章信息
+* @apiDescription 后台管理员更新文章信息，已发布的文章需要article.pass权限，没有article.pass权限时修改审核通过的文章会回到待审核
 * @apiSampleRequest /article
+* @apiParam {int} publish_at 定时发布时间(unix时间戳)，0表示不定时
+* @apiParam {int} expire_at 自动下线时间(unix时间戳)，0表示不下线，需晚于publish_at
+* @apiParam {string} slug 固定链接，小写字母、数字和-，为空时不修改，修改后旧的slug会跳转到新的
+* @apiParam {string} format 内容格式，html或markdown，默认html
+* @apiParam {string} markdown markdown原文，format为markdown时content由原文生成
 * @apiSuccess {bool} state 状态
 * @apiSuccess {String} msg 消息
 * @apiPermission admin
@@ -40,12 +104,24 @@ func ArticleUpdate(ctx *iris.Context) {
 	err := ctx.ReadJSON(&article)
 	if err != nil {
 		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
 	}
 	err1 := validate.Struct(article)
 	if err1 != nil {
 		ctx.JSON(iris.StatusOK, `{"state": false, "msg": `+err1.Error()+`}`)
+		return
+	}
+	if err2 := validate.Var(article.ID, "required,min=1"); err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ids := []int{article.ID}
+	before := model.ArticleList(ids)
+	result := logic.ArticleUpdate(article, currentUserAdmin(ctx))
+	if result.State {
+		auditLog(ctx, "article.update", ids, before, model.ArticleList(ids))
 	}
-	ctx.JSON(iris.StatusOK, model.ArticleUpdate(article))
+	ctx.JSON(iris.StatusOK, result)
 }
 
 /**
@@ -56,8 +132,13 @@ func ArticleUpdate(ctx *iris.Context) {
 * @apiDescription 创建文章信息
 * @apiSampleRequest /article
 * @apiParam {string} title title
-* @apiParam {string} brief brief
+* @apiParam {string} brief 摘要，为空时从内容自动提取
 * @apiParam {string} content content
+* @apiParam {string} format 内容格式，html或markdown，默认html
+* @apiParam {string} markdown markdown原文，format为markdown时content由原文生成
+* @apiParam {int} publish_at 定时发布时间(unix时间戳)，0表示不定时
+* @apiParam {int} expire_at 自动下线时间(unix时间戳)，0表示不下线，需晚于publish_at
+* @apiParam {string} slug 固定链接，小写字母、数字和-，为空时根据标题的拼音生成
 * @apiSuccess {bool} state 状态
 * @apiSuccess {String} msg 消息
 * @apiPermission admin
@@ -67,13 +148,42 @@ func ArticleCreate(ctx *iris.Context) {
 	err := ctx.ReadJSON(&article)
 	if err != nil {
 		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
 	}
 	err1 := validate.Struct(article)
 	if err1 != nil {
 		ctx.JSON(iris.StatusOK, `{"state": false, "msg": `+err1.Error()+`}`)
+		return
 	}
 	article.Createtime = time.Now().Unix()
-	ctx.JSON(iris.StatusOK, model.ArticleCreate(article))
+	result := logic.ArticleCreate(article, currentUserAdmin(ctx))
+	if id, ok := result.Msg.(int); ok && result.State {
+		article.ID = id
+		auditLog(ctx, "article.create", []int{id}, nil, article)
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {post} /article/preview preview markdown
+* @apiName 预览markdown
+* @apiGroup article
+* @apiVersion 1.0.0
+* @apiDescription 把markdown渲染成过滤后的html，不保存，结果和保存后的content一致
+* @apiSampleRequest /article/preview
+* @apiParam {string} markdown markdown原文
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 渲染后的html
+* @apiPermission admin
+ */
+func ArticlePreview(ctx *iris.Context) {
+	markdown := ctx.FormValueString("markdown")
+	err1 := validate.Var(markdown, "required,max=20000")
+	if err1 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.MarkdownPreview(markdown))
 }
 
 /**
@@ -92,12 +202,46 @@ func ArticleCreate(ctx *iris.Context) {
 * @apiPermission admin
  */
 func ArticlePage(ctx *iris.Context) {
-	cp := Tools.ParseInt(ctx.Param("cp"), 1)
-	mp := Tools.ParseInt(ctx.Param("mp"), 20)
-	nodeid := Tools.ParseInt(ctx.Param("nodeid"), 0)
-	kw := ctx.Param("kw")
+	cp := Tools.ParseInt(ctx.FormValueString("cp"), 1)
+	mp := Tools.ParseInt(ctx.FormValueString("mp"), 20)
+	nodeid := Tools.ParseInt(ctx.FormValueString("nodeid"), 0)
+	kw := ctx.FormValueString("kw")
+	err1 := validate.Var(cp, "required,min=1")
+	err2 := validate.Var(mp, "required,min=1,max=50")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.ArticlePageScoped(kw, nodeid, cp, mp, currentUserAdmin(ctx).ID))
+}
 
-	ctx.JSON(iris.StatusOK, model.ArticlePage(kw, nodeid, cp, mp))
+/**
+* @api {post} /article/list list article
+* @apiName 前台文章列表
+* @apiGroup article
+* @apiVersion 1.0.0
+* @apiDescription 前台文章列表，只返回已发布、到了发布时间且没有下线的文章，隐藏节点下的文章不返回，按节点设置的排序方式排序
+* @apiSampleRequest /article/list
+* @apiParam {string} kw 关键字
+* @apiParam {int} cp cp，最大1000
+* @apiParam {int} mp mp，不传时使用节点设置的每页文章数
+* @apiParam {nodeid} nodeid 节点id
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} count 总数
+ */
+func ArticleList(ctx *iris.Context) {
+	cp := Tools.ParseInt(ctx.FormValueString("cp"), 1)
+	mp := Tools.ParseInt(ctx.FormValueString("mp"), 0)
+	nodeid := Tools.ParseInt(ctx.FormValueString("nodeid"), 0)
+	kw := ctx.FormValueString("kw")
+	err1 := validate.Var(cp, "required,min=1,max=1000")
+	err2 := validate.Var(mp, "min=0,max=50")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.ArticlePage(kw, nodeid, cp, mp, true))
 }
 
 /**
@@ -105,17 +249,21 @@ func ArticlePage(ctx *iris.Context) {
 * @apiName delete article
 * @apiGroup article
 * @apiVersion 1.0.0
-* @apiDescription delete article by ids[]
+* @apiDescription 删除文章，文章移到回收站，可以恢复，超过保留天数后自动彻底删除
 * @apiSampleRequest /article
 * @apiParam {string} id 文章id，可传多个用逗号隔开
 * @apiSuccess {bool} state 状态
-* @apiSuccess {String} msg 消息
+* @apiSuccess {int[]} msg 移到回收站的文章id
 * @apiPermission admin
  */
 func ArticleDele(ctx *iris.Context) {
-	ids := ctx.Param("id")
-	ctx.JSON(iris.StatusOK, logic.ArticleDele(ids))
-
+	ids := ctx.FormValueString("id")
+	before := model.ArticleList(Tools.ParseIds(ids))
+	result := logic.ArticleDele(ids, currentUserAdmin(ctx))
+	if trashed, ok := result.Msg.([]int); ok && len(trashed) > 0 {
+		auditLog(ctx, "article.delete", trashed, before, nil)
+	}
+	ctx.JSON(iris.StatusOK, result)
 }
 
 /**
@@ -123,7 +271,7 @@ func ArticleDele(ctx *iris.Context) {
 * @apiName pass article
 * @apiGroup article
 * @apiVersion 1.0.0
-* @apiDescription delete article by ids[]
+* @apiDescription 兼容旧接口，按编辑流程修改状态：pass=1时发布(待审核的文章先通过审核)，已发布的文章pass=0时归档，不允许的流转会返回失败，新接口见/article/workflow
 * @apiSampleRequest /article/pass
 * @apiParam {string} id 用户id
 * @apiParam {string} pass  pass状态
@@ -132,8 +280,12 @@ func ArticleDele(ctx *iris.Context) {
 * @apiPermission admin
  */
 func ArticlePass(ctx *iris.Context) {
-	ids := ctx.Param("id")
-	pass := Tools.ParseInt(ctx.Param("pass"), 0)
-	ctx.JSON(iris.StatusOK, logic.ArticlePass(ids, pass))
-
+	ids := ctx.FormValueString("id")
+	pass := Tools.ParseInt(ctx.FormValueString("pass"), 0)
+	before := model.ArticleList(Tools.ParseIds(ids))
+	result := logic.ArticlePass(ids, pass, currentUserAdmin(ctx))
+	if result.State {
+		auditLog(ctx, "article.pass", Tools.ParseIds(ids), before, model.ArticleList(Tools.ParseIds(ids)))
+	}
+	ctx.JSON(iris.StatusOK, result)
 }
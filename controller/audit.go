@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
+)
+
+/**
+* @api {post} /audit/page page audit
+* @apiName 获取审计日志列表
+* @apiGroup audit
+* @apiVersion 1.0.0
+* @apiDescription 获取后台管理员的操作记录，按时间降序
+* @apiSampleRequest /audit/page
+* @apiParam {int} uid 操作人id，可选
+* @apiParam {string} action 操作，如article.update，可选
+* @apiParam {int} id 操作对象id，可选
+* @apiParam {int} start 开始时间(unix时间戳)，可选
+* @apiParam {int} end 结束时间(unix时间戳)，可选
+* @apiParam {int} cp cp
+* @apiParam {int} mp mp
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} count 总数
+* @apiSuccess {int} --uid 操作人id
+* @apiSuccess {string} --username 操作人用户名
+* @apiSuccess {string} --action 操作
+* @apiSuccess {int[]} --ids 操作对象id
+* @apiSuccess {object} --before 操作前的数据
+* @apiSuccess {object} --after 操作后的数据
+* @apiSuccess {string} --ip 客户端ip
+* @apiSuccess {int} --time 操作时间
+* @apiPermission admin
+ */
+func AuditPage(ctx *iris.Context) {
+	cp := Tools.ParseInt(ctx.FormValueString("cp"), 1)
+	mp := Tools.ParseInt(ctx.FormValueString("mp"), 20)
+	uid := Tools.ParseInt(ctx.FormValueString("uid"), 0)
+	id := Tools.ParseInt(ctx.FormValueString("id"), 0)
+	start := Tools.ParseInt(ctx.FormValueString("start"), 0)
+	end := Tools.ParseInt(ctx.FormValueString("end"), 0)
+	action := ctx.FormValueString("action")
+	err1 := validate.Var(cp, "required,min=1")
+	err2 := validate.Var(mp, "required,min=1,max=100")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.AuditPage(uid, action, id, int64(start), int64(end), cp, mp))
+}
+
+//////////私有方法
+/**
+ * 记录当前管理员的操作，需要在AuthAdmin之后调用
+ * @method auditLog
+ */
+func auditLog(ctx *iris.Context, action string, ids []int, before interface{}, after interface{}) {
+	logic.AuditLog(currentUserAdmin(ctx), clientIp(ctx), action, ids, before, after)
+}
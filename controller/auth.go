@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
+	"pizzaCmsApi/model"
+	"strings"
+)
+
+/**
+ * 管理员登录校验中间件，用于@apiPermission admin的路由
+ * token从header Authorization: Bearer <token> 或者参数token中获取
+ * 校验通过后把当前管理员放入ctx，key为useradmin
+ * @method AuthAdmin
+ */
+func AuthAdmin(ctx *iris.Context) {
+	token := getToken(ctx)
+	user, ok := logic.SessionCheck(token)
+	if !ok {
+		ctx.JSON(iris.StatusUnauthorized, model.ApiJson{State: false, Msg: "not login"})
+		return
+	}
+	ctx.Set("useradmin", user)
+	ctx.Set("token", token)
+	ctx.Next()
+}
+
+/**
+ * 权限校验中间件，需要放在AuthAdmin之后
+ * @method Permission
+ * @param  {[type]} perm string 权限标识，见logic.Permissions
+ */
+func Permission(perm string) iris.HandlerFunc {
+	return func(ctx *iris.Context) {
+		if !logic.HasPermission(currentUserAdmin(ctx).ID, perm) {
+			ctx.JSON(iris.StatusForbidden, model.ApiJson{State: false, Msg: "permission denied"})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+//////////私有方法
+/**
+ * 从请求中获取token
+ * @method getToken
+ */
+func getToken(ctx *iris.Context) string {
+	auth := ctx.RequestHeader("Authorization")
+	if strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ctx.FormValueString("token")
+}
+
+/**
+ * 获取当前登录的管理员，需要在AuthAdmin之后调用
+ * @method currentUserAdmin
+ */
+func currentUserAdmin(ctx *iris.Context) model.UserAdmin {
+	user, _ := ctx.Get("useradmin").(model.UserAdmin)
+	return user
+}
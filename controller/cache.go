@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
+	"strings"
+)
+
+/**
+* @api {post} /cache/stats cache stats
+* @apiName 缓存命中统计
+* @apiGroup cache
+* @apiVersion 1.0.0
+* @apiDescription 按缓存名称统计命中和未命中次数，如article、article.page
+* @apiSampleRequest /cache/stats
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} --hit 命中次数
+* @apiSuccess {int} --miss 未命中次数
+* @apiSuccess {float} --ratio 命中率
+* @apiPermission admin
+ */
+func CacheStats(ctx *iris.Context) {
+	ctx.JSON(iris.StatusOK, logic.CacheStats())
+}
+
+/**
+* @api {post} /cache/clear clear cache
+* @apiName 清除缓存
+* @apiGroup cache
+* @apiVersion 1.0.0
+* @apiDescription 按标签清除缓存。article:list文章列表，article:文章id单篇文章，node包含节点信息的数据
+* @apiSampleRequest /cache/clear
+* @apiParam {string} tags 缓存标签，可传多个用逗号隔开
+* @apiParam {int} reset 为1时同时清空命中统计
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func CacheClear(ctx *iris.Context) {
+	var tags []string
+	for _, tag := range strings.Split(ctx.FormValueString("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	reset := ctx.FormValueString("reset") == "1"
+	if len(tags) == 0 && !reset {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	result := logic.CacheClear(tags, reset)
+	auditLog(ctx, "cache.clear", nil, nil, map[string]interface{}{"tags": tags, "reset": reset})
+	ctx.JSON(iris.StatusOK, result)
+}
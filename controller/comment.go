@@ -2,6 +2,7 @@ package controller
 
 import (
 	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
 	"pizzaCmsApi/model"
 )
 
@@ -10,7 +11,7 @@ import (
  * @apiName get comment
  * @apiGroup comment
  * @apiVersion 1.0.0
- * @apiDescription 获取文章评论信息
+ * @apiDescription 获取文章评论信息，未登录时只能获取前台可见的文章下审核通过的评论
  * @apiSampleRequest /comment/:id
  * @apiParam {int} id文章评论的id
  * @apiSuccess {bool} state 状态
@@ -21,14 +22,17 @@ import (
  * @apiSuccess {string} --content 评论内容
  * @apiSuccess {int} --uid 用户id
  * @apiSuccess {string} --username 用户昵称
+ * @apiSuccess {int} --pass 审核状态：0待审核，1通过，2不通过
  */
 func CommentGet(ctx *iris.Context) {
 	id := Tools.ParseInt(ctx.Param("id"), 0)
 	err1 := validate.Var(id, "omitempty,min=1")
 	if err1 != nil {
 		ctx.JSON(iris.StatusOK, errorValidate())
+		return
 	}
-	ctx.JSON(iris.StatusOK, model.CommentGet(id))
+	_, admin := logic.SessionCheck(getToken(ctx))
+	ctx.JSON(iris.StatusOK, model.CommentGet(id, !admin))
 }
 
 /**
@@ -36,9 +40,10 @@ func CommentGet(ctx *iris.Context) {
 * @apiName update comment
 * @apiGroup comment
 * @apiVersion 1.0.0
-* @apiDescription 更新文章评论信息
+* @apiDescription 修改评论内容，文章、评论人和时间不能修改
 * @apiSampleRequest /comment
-* @apiParam {Comment} comment
+* @apiParam {int} id 评论id
+* @apiParam {string} content 评论内容
 * @apiSuccess {bool} state 状态
 * @apiSuccess {String} msg 消息
 * @apiPermission admin
@@ -48,12 +53,24 @@ func CommentUpdate(ctx *iris.Context) {
 	err := ctx.ReadJSON(&comment)
 	if err != nil {
 		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
 	}
 	err1 := validate.Struct(comment)
 	if err1 != nil {
 		ctx.JSON(iris.StatusOK, `{"state": false, "msg": `+err1.Error()+`}`)
+		return
 	}
-	ctx.JSON(iris.StatusOK, model.CommentUpdate(comment))
+	if err2 := validate.Var(comment.Id, "required,min=1"); err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ids := []int{comment.Id}
+	before := model.CommentList(ids)
+	result := logic.CommentUpdate(comment)
+	if result.State {
+		auditLog(ctx, "comment.update", ids, before, model.CommentList(ids))
+	}
+	ctx.JSON(iris.StatusOK, result)
 }
 
 /**
@@ -61,24 +78,37 @@ func CommentUpdate(ctx *iris.Context) {
 * @apiName create comment
 * @apiGroup comment
 * @apiVersion 1.0.0
-* @apiDescription 创建文章评论信息
+* @apiDescription 创建文章评论，不需要登录，只能评论前台可见的文章。文章所在节点关闭评论时返回失败，需要审核时评论为待审核状态
 * @apiSampleRequest /comment
-* @apiParam {Comment} comment 文章评论信息
+* @apiParam {int} articleid 文章id
+* @apiParam {string} content 评论内容
+* @apiParam {string} username 显示的昵称，未登录时评论的uid为0，带管理员token时uid为当前管理员，不传昵称时使用管理员的昵称
 * @apiSuccess {bool} state 状态
-* @apiSuccess {String} msg 消息
-* @apiPermission admin
+* @apiSuccess {String} msg 评论id
  */
 func CommentCreate(ctx *iris.Context) {
 	var comment model.Comment
 	err := ctx.ReadJSON(&comment)
 	if err != nil {
 		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
 	}
 	err1 := validate.Struct(comment)
 	if err1 != nil {
 		ctx.JSON(iris.StatusOK, `{"state": false, "msg": `+err1.Error()+`}`)
+		return
+	}
+	if err2 := validate.Var(comment.Username, "max=30"); err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	user, admin := logic.SessionCheck(getToken(ctx))
+	result := logic.CommentCreate(comment, user)
+	//管理员带token评论时记录操作，读者的评论不记录
+	if id, ok := result.Msg.(int); ok && result.State && admin {
+		logic.AuditLog(user, clientIp(ctx), "comment.create", []int{id}, nil, model.CommentList([]int{id}))
 	}
-	ctx.JSON(iris.StatusOK, model.CommentCreate(comment))
+	ctx.JSON(iris.StatusOK, result)
 }
 
 /**
@@ -91,21 +121,55 @@ func CommentCreate(ctx *iris.Context) {
 * @apiParam {string} kw 关键字
 * @apiParam {int} cp cp
 * @apiParam {int} mp mp
+* @apiParam {int} pass 审核状态：0待审核，1通过，2不通过，不传表示全部
 * @apiSuccess {bool} state 状态
 * @apiSuccess {String} msg 消息
 * @apiPermission admin
  */
 func CommentPage(ctx *iris.Context) {
-	cp := Tools.ParseInt(ctx.Param("cp"), 1)
-	mp := Tools.ParseInt(ctx.Param("mp"), 20)
-	kw := ctx.Param("kw")
-	err1 := validate.Var(kw, "required, min=1,max=20")
+	cp := Tools.ParseInt(ctx.FormValueString("cp"), 1)
+	mp := Tools.ParseInt(ctx.FormValueString("mp"), 20)
+	kw := ctx.FormValueString("kw")
+	pass := Tools.ParseInt(ctx.FormValueString("pass"), -1)
+	err1 := validate.Var(kw, "max=20")
 	err2 := validate.Var(cp, "required,min=1")
 	err3 := validate.Var(mp, "required,min=1,max=50")
-	if err1 != nil || err2 != nil || err3 != nil {
+	err4 := validate.Var(pass, "min=-1,max=2")
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, model.CommentPage(kw, cp, mp, pass))
+}
+
+/**
+* @api {post} /comment/pass 审核文章评论
+* @apiName pass comment
+* @apiGroup comment
+* @apiVersion 1.0.0
+* @apiDescription 审核评论，需要审核的节点下新评论为待审核状态，审核通过后才在前台显示并计入文章的评论数
+* @apiSampleRequest /comment/pass
+* @apiParam {string} id 评论id，可传多个用逗号隔开
+* @apiParam {int} pass 1通过，2不通过
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func CommentPass(ctx *iris.Context) {
+	ids := ctx.FormValueString("id")
+	pass := Tools.ParseInt(ctx.FormValueString("pass"), 0)
+	err1 := validate.Var(ids, "required,max=1000")
+	err2 := validate.Var(pass, "required,min=1,max=2")
+	if err1 != nil || err2 != nil {
 		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	before := model.CommentList(Tools.ParseIds(ids))
+	result := logic.CommentPass(ids, pass)
+	if result.State {
+		auditLog(ctx, "comment.pass", Tools.ParseIds(ids), before, model.CommentList(Tools.ParseIds(ids)))
 	}
-	ctx.JSON(iris.StatusOK, model.CommentPage(kw, cp, mp))
+	ctx.JSON(iris.StatusOK, result)
 }
 
 /**
@@ -113,22 +177,23 @@ func CommentPage(ctx *iris.Context) {
 * @apiName delete comment
 * @apiGroup comment
 * @apiVersion 1.0.0
-* @apiDescription delete comment by id
+* @apiDescription 删除评论，可以删除任何用户的评论
 * @apiSampleRequest /comment
-* @apiParam {string} id 文章评论id
-* @apiParam {int} uid 用户的uid
+* @apiParam {int} id 文章评论id
 * @apiSuccess {bool} state 状态
 * @apiSuccess {String} msg 消息
 * @apiPermission admin
  */
 func CommentDele(ctx *iris.Context) {
-	ids := Tools.ParseInt(ctx.Param("id"), 0)
-	uid := Tools.ParseInt(ctx.Param("uid"), 0)
-	err1 := validate.Var(ids, "required, min=1")
-	err2 := validate.Var(uid, "required, min=1")
-	if err1 != nil || err2 != nil {
+	id := Tools.ParseInt(ctx.FormValueString("id"), 0)
+	if err := validate.Var(id, "required,min=1"); err != nil {
 		ctx.JSON(iris.StatusOK, errorValidate())
+		return
 	}
-	ctx.JSON(iris.StatusOK, model.CommentDel(ids, uid))
-
+	before := model.CommentList([]int{id})
+	result := logic.CommentDele(id)
+	if result.State {
+		auditLog(ctx, "comment.delete", []int{id}, before, nil)
+	}
+	ctx.JSON(iris.StatusOK, result)
 }
@@ -1,21 +1,33 @@
 package controller
 
 import (
+	"github.com/kataras/iris"
 	"gopkg.in/go-playground/validator.v8"
+	"pizzaCmsApi/config"
 	"pizzaCmsApi/tools"
 )
 
 var (
 	validate *validator.Validate
 	Tools    *tools.Tools
+	Config   *config.Config
 )
 
 func init() {
 	validate = validator.New()
 	Tools = tools.New()
+	Config = config.New()
 }
 
 //////////私有方法
+/**
+ * 客户端ip，iris的RemoteAddr直接使用请求头，可以伪造，这里只信任配置的代理
+ * @method clientIp
+ */
+func clientIp(ctx *iris.Context) string {
+	return Tools.ClientIp(ctx.RequestCtx.RemoteIP().String(), ctx.RequestHeader("X-Forwarded-For"), ctx.RequestHeader("X-Real-Ip"), Config.App.Proxies)
+}
+
 /**
  * 返回数据格式不合法的字符串
  * @method ErrorValidate
@@ -0,0 +1,297 @@
+package controller
+
+import (
+	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
+	"pizzaCmsApi/model"
+)
+
+/**
+* @api {post} /menu/get get menu
+* @apiName 前台获取菜单
+* @apiGroup menu
+* @apiVersion 1.0.0
+* @apiDescription 按调用标识获取菜单树，节点和文章的名称、链接按当前数据填充，隐藏的节点和前台不可见的文章连同下级菜单项一起去掉
+* @apiSampleRequest /menu/get
+* @apiParam {string} name 菜单调用标识，如main
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {string} --kind 类型，node、article或link
+* @apiSuccess {int} --targetid 节点或文章id
+* @apiSuccess {string} --title 名称
+* @apiSuccess {string} --url 链接
+* @apiSuccess {string} --slug 文章的固定链接
+* @apiSuccess {MenuItem[]} --children 下级菜单项
+ */
+func MenuGet(ctx *iris.Context) {
+	name := ctx.FormValueString("name")
+	if err := validate.Var(name, "required,max=30"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.MenuGet(name))
+}
+
+/**
+* @api {post} /menu/page get all menu
+* @apiName 获取所有菜单
+* @apiGroup menu
+* @apiVersion 1.0.0
+* @apiDescription 获取所有菜单
+* @apiSampleRequest /menu/page
+* @apiSuccess {bool} state 状态
+* @apiSuccess {Menu[]} msg 菜单列表
+* @apiPermission admin
+ */
+func MenuPage(ctx *iris.Context) {
+	menus := model.MenuAll()
+	ctx.JSON(iris.StatusOK, model.ApiJson{State: true, Msg: menus, Count: len(menus)})
+}
+
+/**
+* @api {post} /menu create menu
+* @apiName 创建菜单
+* @apiGroup menu
+* @apiVersion 1.0.0
+* @apiDescription 创建菜单
+* @apiSampleRequest /menu
+* @apiParam {string} name 调用标识，唯一
+* @apiParam {string} title 名称
+* @apiSuccess {bool} state 状态
+* @apiSuccess {int} msg 菜单id
+* @apiPermission admin
+ */
+func MenuCreate(ctx *iris.Context) {
+	var menu model.Menu
+	if err := ctx.ReadJSON(&menu); err != nil {
+		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
+	}
+	if err := validate.Struct(menu); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	result := logic.MenuCreate(menu)
+	if id, ok := result.Msg.(int); ok && result.State {
+		auditLog(ctx, "menu.create", []int{id}, nil, model.MenuFind(id))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {PUT} /menu update menu
+* @apiName 更新菜单
+* @apiGroup menu
+* @apiVersion 1.0.0
+* @apiDescription 更新菜单的调用标识和名称
+* @apiSampleRequest /menu
+* @apiParam {int} id 菜单id
+* @apiParam {string} name 调用标识，唯一
+* @apiParam {string} title 名称
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func MenuUpdate(ctx *iris.Context) {
+	var menu model.Menu
+	if err := ctx.ReadJSON(&menu); err != nil {
+		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
+	}
+	err1 := validate.Struct(menu)
+	err2 := validate.Var(menu.ID, "required,min=1")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	before := model.MenuFind(menu.ID)
+	result := logic.MenuUpdate(menu)
+	if result.State {
+		auditLog(ctx, "menu.update", []int{menu.ID}, before, model.MenuFind(menu.ID))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {delete} /menu delete menu
+* @apiName 删除菜单
+* @apiGroup menu
+* @apiVersion 1.0.0
+* @apiDescription 删除菜单和它的所有菜单项
+* @apiSampleRequest /menu
+* @apiParam {int} id 菜单id
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func MenuDele(ctx *iris.Context) {
+	id := Tools.ParseInt(ctx.FormValueString("id"), 0)
+	if err := validate.Var(id, "required,min=1"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	before := model.MenuFind(id)
+	result := logic.MenuDele(id)
+	if result.State {
+		auditLog(ctx, "menu.delete", []int{id}, before, nil)
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {post} /menu/items menu items
+* @apiName 后台获取菜单项
+* @apiGroup menu
+* @apiVersion 1.0.0
+* @apiDescription 菜单的所有菜单项组成的树，不过滤不可见的目标，用于编辑
+* @apiSampleRequest /menu/items
+* @apiParam {int} menuid 菜单id
+* @apiSuccess {bool} state 状态
+* @apiSuccess {MenuItem[]} msg 菜单项树
+* @apiPermission admin
+ */
+func MenuItems(ctx *iris.Context) {
+	menuid := Tools.ParseInt(ctx.FormValueString("menuid"), 0)
+	if err := validate.Var(menuid, "required,min=1"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.MenuItemTree(menuid))
+}
+
+/**
+* @api {post} /menu/item create menu item
+* @apiName 创建菜单项
+* @apiGroup menu
+* @apiVersion 1.0.0
+* @apiDescription 创建菜单项，排在同级菜单项的最后
+* @apiSampleRequest /menu/item
+* @apiParam {int} menuid 菜单id
+* @apiParam {int} pid 上级菜单项id，0表示顶级
+* @apiParam {string} kind 类型，node、article或link
+* @apiParam {int} targetid 节点或文章id，kind为link时忽略
+* @apiParam {string} url 链接地址，kind为link时必填，只允许http://、https://或/开头
+* @apiParam {string} title 名称，为空时使用节点名称或文章标题，kind为link时必填
+* @apiSuccess {bool} state 状态
+* @apiSuccess {int} msg 菜单项id
+* @apiPermission admin
+ */
+func MenuItemCreate(ctx *iris.Context) {
+	var item model.MenuItem
+	if err := ctx.ReadJSON(&item); err != nil {
+		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
+	}
+	err1 := validate.Struct(item)
+	err2 := validate.Var(item.Menuid, "required,min=1")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	result := logic.MenuItemCreate(item)
+	if id, ok := result.Msg.(int); ok && result.State {
+		auditLog(ctx, "menu.item.create", []int{id}, nil, model.MenuItemFind(id))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {PUT} /menu/item update menu item
+* @apiName 更新菜单项
+* @apiGroup menu
+* @apiVersion 1.0.0
+* @apiDescription 更新菜单项的类型、链接和名称，位置通过/menu/item/sort修改
+* @apiSampleRequest /menu/item
+* @apiParam {int} id 菜单项id
+* @apiParam {string} kind 类型，node、article或link
+* @apiParam {int} targetid 节点或文章id
+* @apiParam {string} url 链接地址
+* @apiParam {string} title 名称
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func MenuItemUpdate(ctx *iris.Context) {
+	var item model.MenuItem
+	if err := ctx.ReadJSON(&item); err != nil {
+		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
+	}
+	err1 := validate.Struct(item)
+	err2 := validate.Var(item.ID, "required,min=1")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	before := model.MenuItemFind(item.ID)
+	result := logic.MenuItemUpdate(item)
+	if result.State {
+		auditLog(ctx, "menu.item.update", []int{item.ID}, before, model.MenuItemFind(item.ID))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {delete} /menu/item delete menu item
+* @apiName 删除菜单项
+* @apiGroup menu
+* @apiVersion 1.0.0
+* @apiDescription 删除菜单项，下级菜单项一起删除
+* @apiSampleRequest /menu/item
+* @apiParam {int} id 菜单项id
+* @apiSuccess {bool} state 状态
+* @apiSuccess {int[]} msg 删除的菜单项id
+* @apiPermission admin
+ */
+func MenuItemDele(ctx *iris.Context) {
+	id := Tools.ParseInt(ctx.FormValueString("id"), 0)
+	if err := validate.Var(id, "required,min=1"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	before := model.MenuItemFind(id)
+	result := logic.MenuItemDele(id)
+	if ids, ok := result.Msg.([]int); ok && result.State {
+		auditLog(ctx, "menu.item.delete", ids, before, nil)
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {post} /menu/item/sort sort menu item
+* @apiName 菜单项排序
+* @apiGroup menu
+* @apiVersion 1.0.0
+* @apiDescription 保存拖动排序的结果，可以同时修改上级菜单项，不能把菜单项移到自己的下级
+* @apiSampleRequest /menu/item/sort
+* @apiParam {int} menuid 菜单id
+* @apiParam {Object[]} items 需要修改位置的菜单项
+* @apiParam {int} --id 菜单项id
+* @apiParam {int} --pid 上级菜单项id，0表示顶级
+* @apiParam {int} --sort 同级中的顺序，小的在前
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func MenuItemSort(ctx *iris.Context) {
+	var form struct {
+		Menuid int                   `json:"menuid"`
+		Items  []model.MenuItemOrder `json:"items"`
+	}
+	if err := ctx.ReadJSON(&form); err != nil {
+		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
+	}
+	err1 := validate.Var(form.Menuid, "required,min=1")
+	err2 := validate.Var(form.Items, "required,min=1")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	before := model.MenuItems(form.Menuid)
+	result := logic.MenuItemSort(form.Menuid, form.Items)
+	if result.State {
+		auditLog(ctx, "menu.item.sort", []int{form.Menuid}, before, model.MenuItems(form.Menuid))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
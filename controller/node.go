@@ -0,0 +1,296 @@
+package controller
+
+import (
+	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
+	"pizzaCmsApi/model"
+)
+
+/**
+ * @api {get} /node/:id 获取节点
+ * @apiName get node
+ * @apiGroup node
+ * @apiVersion 1.0.0
+ * @apiDescription 获取节点信息
+ * @apiSampleRequest /node/:id
+ * @apiParam {int} id 节点id
+ * @apiSuccess {bool} state 状态
+ * @apiSuccess {String} msg 消息
+ * @apiSuccess {int} --id 节点id
+ * @apiSuccess {int} --pid 上级节点id
+ * @apiSuccess {string} --name 名称
+ * @apiSuccess {string} --brief 简介
+ * @apiSuccess {string} --nodepath 节点路径，如,1,3,9,
+ * @apiSuccess {string} --link 链接
+ * @apiSuccess {int} --weight 权重
+ * @apiSuccess {string} --visibility 可见性，public或hidden，为空时继承上级节点
+ * @apiSuccess {int} --hidden 继承后是否隐藏
+ * @apiSuccess {string} --comment 评论策略，enabled、moderated或disabled，为空时继承
+ * @apiSuccess {string} --sort 文章默认排序，new、old、reco、view或comment，为空时继承
+ * @apiSuccess {int} --pagesize 每页文章数，0表示继承
+ * @apiSuccess {string} --seo_title SEO标题
+ * @apiSuccess {string} --seo_keywords SEO关键字
+ */
+func NodeGet(ctx *iris.Context) {
+	id := Tools.ParseInt(ctx.Param("id"), 0)
+	err1 := validate.Var(id, "required,min=1")
+	if err1 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, model.NodeGet(id))
+}
+
+/**
+ * @api {get} /node/:id/breadcrumb 面包屑导航
+ * @apiName node breadcrumb
+ * @apiGroup node
+ * @apiVersion 1.0.0
+ * @apiDescription 从顶级节点到当前节点的路径，隐藏的节点返回不存在
+ * @apiSampleRequest /node/:id/breadcrumb
+ * @apiParam {int} id 节点id
+ * @apiSuccess {bool} state 状态
+ * @apiSuccess {String} msg 消息
+ * @apiSuccess {int} --id 节点id
+ * @apiSuccess {string} --name 名称
+ * @apiSuccess {string} --link 链接
+ */
+func NodeBreadcrumb(ctx *iris.Context) {
+	id := Tools.ParseInt(ctx.Param("id"), 0)
+	if err := validate.Var(id, "required,min=1"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.NodeBreadcrumb(id))
+}
+
+/**
+* @api {post} /node/tree node tree
+* @apiName 节点树
+* @apiGroup node
+* @apiVersion 1.0.0
+* @apiDescription 前台节点树，不包含隐藏的节点，同级节点按权重降序
+* @apiSampleRequest /node/tree
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {Node[]} --children 下级节点
+ */
+func NodeTree(ctx *iris.Context) {
+	ctx.JSON(iris.StatusOK, logic.NodeTree(true))
+}
+
+/**
+* @api {post} /node/tree/all node tree all
+* @apiName 后台节点树
+* @apiGroup node
+* @apiVersion 1.0.0
+* @apiDescription 所有节点组成的树，包含隐藏的节点
+* @apiSampleRequest /node/tree/all
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {Node[]} --children 下级节点
+* @apiPermission admin
+ */
+func NodeTreeAll(ctx *iris.Context) {
+	ctx.JSON(iris.StatusOK, logic.NodeTree(false))
+}
+
+/**
+* @api {post} /node/setting node setting
+* @apiName 节点设置
+* @apiGroup node
+* @apiVersion 1.0.0
+* @apiDescription 节点继承上级节点后的设置，隐藏的节点返回不存在
+* @apiSampleRequest /node/setting
+* @apiParam {int} id 节点id
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {bool} --hidden 是否隐藏
+* @apiSuccess {string} --comment 评论策略
+* @apiSuccess {string} --sort 文章默认排序
+* @apiSuccess {int} --pagesize 每页文章数
+* @apiSuccess {string} --seo_title SEO标题，没有设置时为节点名称
+* @apiSuccess {string} --seo_keywords SEO关键字
+ */
+func NodeSetting(ctx *iris.Context) {
+	id := Tools.ParseInt(ctx.FormValueString("id"), 0)
+	if err := validate.Var(id, "required,min=1"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.NodeSettingPublic(id))
+}
+
+/**
+* @api {post} /node create node
+* @apiName 创建节点
+* @apiGroup node
+* @apiVersion 1.0.0
+* @apiDescription 创建节点，nodepath根据上级节点自动生成
+* @apiSampleRequest /node
+* @apiParam {int} pid 上级节点id，0表示顶级
+* @apiParam {string} name 名称
+* @apiParam {string} brief 简介
+* @apiParam {string} link 链接
+* @apiParam {int} weight 权重，同级节点中权重大的在前
+* @apiParam {string} visibility 可见性，public或hidden，为空时继承上级节点
+* @apiParam {string} comment 评论策略，enabled、moderated或disabled，为空时继承
+* @apiParam {string} sort 文章默认排序，new、old、reco、view或comment，为空时继承
+* @apiParam {int} pagesize 每页文章数，最大50，0表示继承
+* @apiParam {string} seo_title SEO标题，为空时使用节点名称
+* @apiParam {string} seo_keywords SEO关键字，为空时继承
+* @apiSuccess {bool} state 状态
+* @apiSuccess {int} msg 节点id
+* @apiPermission admin
+ */
+func NodeCreate(ctx *iris.Context) {
+	var node model.Node
+	if err := ctx.ReadJSON(&node); err != nil {
+		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
+	}
+	if err := validate.Struct(node); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	result := logic.NodeCreate(node)
+	if id, ok := result.Msg.(int); ok && result.State {
+		auditLog(ctx, "node.create", []int{id}, nil, model.NodeFind(id))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {PUT} /node update node
+* @apiName 更新节点
+* @apiGroup node
+* @apiVersion 1.0.0
+* @apiDescription 更新节点的名称、简介、链接、权重和节点设置，不修改上级节点
+* @apiSampleRequest /node
+* @apiParam {int} id 节点id
+* @apiParam {string} name 名称
+* @apiParam {string} brief 简介
+* @apiParam {string} link 链接
+* @apiParam {int} weight 权重
+* @apiParam {string} visibility 可见性，public或hidden，为空时继承上级节点
+* @apiParam {string} comment 评论策略，enabled、moderated或disabled，为空时继承
+* @apiParam {string} sort 文章默认排序，new、old、reco、view或comment，为空时继承
+* @apiParam {int} pagesize 每页文章数，最大50，0表示继承
+* @apiParam {string} seo_title SEO标题，为空时使用节点名称
+* @apiParam {string} seo_keywords SEO关键字，为空时继承
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func NodeUpdate(ctx *iris.Context) {
+	var node model.Node
+	if err := ctx.ReadJSON(&node); err != nil {
+		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
+	}
+	err1 := validate.Struct(node)
+	err2 := validate.Var(node.ID, "required,min=1")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	before := model.NodeFind(node.ID)
+	result := logic.NodeUpdate(node)
+	if result.State {
+		auditLog(ctx, "node.update", []int{node.ID}, before, model.NodeFind(node.ID))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {delete} /node delete node
+* @apiName 删除节点
+* @apiGroup node
+* @apiVersion 1.0.0
+* @apiDescription 删除节点。节点有下级节点或文章时必须指定to，下级节点移到to下面，文章改为属于to，否则拒绝删除
+* @apiSampleRequest /node
+* @apiParam {int} id 节点id
+* @apiParam {int} to 下级节点和文章转移到的节点id，可选
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} count 转移的文章数
+* @apiPermission admin
+ */
+func NodeDele(ctx *iris.Context) {
+	id := Tools.ParseInt(ctx.FormValueString("id"), 0)
+	to := Tools.ParseInt(ctx.FormValueString("to"), 0)
+	err1 := validate.Var(id, "required,min=1")
+	err2 := validate.Var(to, "min=0")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	before := model.NodeFind(id)
+	result := logic.NodeDele(id, to)
+	if result.State {
+		auditLog(ctx, "node.delete", []int{id}, before, map[string]interface{}{"to": to, "articles": result.Count})
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {post} /node/move move node
+* @apiName 移动节点
+* @apiGroup node
+* @apiVersion 1.0.0
+* @apiDescription 把节点连同下级节点移到新的上级节点下，所有后代节点的nodepath一起更新。不能移到自身或自己的下级节点下
+* @apiSampleRequest /node/move
+* @apiParam {int} id 节点id
+* @apiParam {int} pid 新的上级节点id，0表示移到顶级
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} count 子树中的文章数
+* @apiPermission admin
+ */
+func NodeMove(ctx *iris.Context) {
+	id := Tools.ParseInt(ctx.FormValueString("id"), 0)
+	pid := Tools.ParseInt(ctx.FormValueString("pid"), 0)
+	err1 := validate.Var(id, "required,min=1")
+	err2 := validate.Var(pid, "min=0")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	before := model.NodeFind(id)
+	result := logic.NodeMove(id, pid)
+	if result.State {
+		auditLog(ctx, "node.move", []int{id}, before, model.NodeFind(id))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {post} /node/merge merge node
+* @apiName 合并节点
+* @apiGroup node
+* @apiVersion 1.0.0
+* @apiDescription 把节点合并到目标节点：下级节点移到目标节点下，文章改为属于目标节点，然后删除原节点。目标节点不能是原节点的下级节点
+* @apiSampleRequest /node/merge
+* @apiParam {int} id 被合并的节点id
+* @apiParam {int} to 目标节点id
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} count 受影响的文章数
+* @apiPermission admin
+ */
+func NodeMerge(ctx *iris.Context) {
+	id := Tools.ParseInt(ctx.FormValueString("id"), 0)
+	to := Tools.ParseInt(ctx.FormValueString("to"), 0)
+	err1 := validate.Var(id, "required,min=1")
+	err2 := validate.Var(to, "required,min=1")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	before := model.NodeFind(id)
+	result := logic.NodeMerge(id, to)
+	if result.State {
+		auditLog(ctx, "node.merge", []int{id, to}, before, model.NodeFind(to))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
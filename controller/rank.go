@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
+)
+
+/**
+* @api {post} /article/rank article rank
+* @apiName 文章排行榜
+* @apiGroup article
+* @apiVersion 1.0.0
+* @apiDescription 只返回前台可见的文章。today今日热门，week本周热门(越早的浏览权重越低)，all总浏览数，
+* reco推荐(按推荐值和发布时间)，comment评论最多(审核通过的评论)。开启哪些排行榜由配置rank.kinds决定，
+* 所有排行榜都定时从数据库重建，today和week的每天浏览数在redis丢失后从数据库恢复
+* @apiSampleRequest /article/rank
+* @apiParam {string} type 排行榜类型：today、week、all、reco、comment
+* @apiParam {int} nodeid 节点id，包含子节点，0表示全部
+* @apiParam {int} limit 返回数量，默认10
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} count 数量
+* @apiSuccess {float} --score 排行分数
+ */
+func ArticleRank(ctx *iris.Context) {
+	kind := ctx.FormValueString("type")
+	nodeid := Tools.ParseInt(ctx.FormValueString("nodeid"), 0)
+	limit := Tools.ParseInt(ctx.FormValueString("limit"), 10)
+	err1 := validate.Var(kind, "required,max=20")
+	err2 := validate.Var(nodeid, "min=0")
+	err3 := validate.Var(limit, "required,min=1,max=50")
+	if err1 != nil || err2 != nil || err3 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.Rank(kind, nodeid, limit))
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
+	"pizzaCmsApi/model"
+)
+
+/**
+* @api {post} /article/revision/page page revision
+* @apiName 获取文章的历史版本
+* @apiGroup revision
+* @apiVersion 1.0.0
+* @apiDescription 获取文章的历史版本列表，按版本号降序，不包含content，只能查看授权节点下的文章
+* @apiSampleRequest /article/revision/page
+* @apiParam {int} articleid 文章id
+* @apiParam {int} cp cp
+* @apiParam {int} mp mp
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} count 总数
+* @apiSuccess {int} --id 版本id
+* @apiSuccess {int} --version 版本号
+* @apiSuccess {int} --uid 修改人id
+* @apiSuccess {string} --username 修改人用户名
+* @apiSuccess {int} --createtime 修改时间
+* @apiPermission admin
+ */
+func RevisionPage(ctx *iris.Context) {
+	articleid := Tools.ParseInt(ctx.FormValueString("articleid"), 0)
+	cp := Tools.ParseInt(ctx.FormValueString("cp"), 1)
+	mp := Tools.ParseInt(ctx.FormValueString("mp"), 20)
+	err1 := validate.Var(articleid, "required,min=1")
+	err2 := validate.Var(cp, "required,min=1")
+	err3 := validate.Var(mp, "required,min=1,max=50")
+	if err1 != nil || err2 != nil || err3 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.RevisionPage(articleid, cp, mp, currentUserAdmin(ctx)))
+}
+
+/**
+* @api {post} /article/revision/get get revision
+* @apiName 获取文章的某个版本
+* @apiGroup revision
+* @apiVersion 1.0.0
+* @apiDescription 获取某个版本的完整快照，只能查看授权节点下的文章
+* @apiSampleRequest /article/revision/get
+* @apiParam {int} id 版本id
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func RevisionGet(ctx *iris.Context) {
+	id := Tools.ParseInt(ctx.FormValueString("id"), 0)
+	if err := validate.Var(id, "required,min=1"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.RevisionGet(id, currentUserAdmin(ctx)))
+}
+
+/**
+* @api {post} /article/revision/diff diff revision
+* @apiName 对比文章的两个版本
+* @apiGroup revision
+* @apiVersion 1.0.0
+* @apiDescription 对比两个版本的content，同时返回其他有变化的字段，只能查看授权节点下的文章
+* @apiSampleRequest /article/revision/diff
+* @apiParam {int} from 旧版本id
+* @apiParam {int} to 新版本id
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {string[]} --changed content以外有变化的字段
+* @apiSuccess {object[]} --diff content的差异，type为-1删除、0相同、1新增
+* @apiSuccess {string} --html content差异的html
+* @apiPermission admin
+ */
+func RevisionDiff(ctx *iris.Context) {
+	from := Tools.ParseInt(ctx.FormValueString("from"), 0)
+	to := Tools.ParseInt(ctx.FormValueString("to"), 0)
+	err1 := validate.Var(from, "required,min=1")
+	err2 := validate.Var(to, "required,min=1")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.RevisionDiff(from, to, currentUserAdmin(ctx)))
+}
+
+/**
+* @api {post} /article/revision/restore restore revision
+* @apiName 回滚文章到某个版本
+* @apiGroup revision
+* @apiVersion 1.0.0
+* @apiDescription 把文章恢复为某个版本的内容，回滚本身会生成一个新版本，文章所在节点、定时发布和审核状态保持不变
+* @apiSampleRequest /article/revision/restore
+* @apiParam {int} id 版本id
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func RevisionRestore(ctx *iris.Context) {
+	id := Tools.ParseInt(ctx.FormValueString("id"), 0)
+	if err := validate.Var(id, "required,min=1"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	revision := model.RevisionGet(id)
+	ids := []int{revision.Articleid}
+	before := model.ArticleList(ids)
+	result := logic.RevisionRestore(id, currentUserAdmin(ctx))
+	if result.State {
+		auditLog(ctx, "article.restore", ids, before, model.ArticleList(ids))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
+	"pizzaCmsApi/model"
+)
+
+/**
+* @api {post} /useradmin/role/page get all role
+* @apiName 获取所有角色
+* @apiGroup role
+* @apiVersion 1.0.0
+* @apiDescription 获取所有角色
+* @apiSampleRequest /useradmin/role/page
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} --id 角色id
+* @apiSuccess {string} --name 角色标识
+* @apiSuccess {string} --title 角色名称
+* @apiSuccess {string} --permissions 权限列表，逗号隔开
+* @apiPermission admin
+ */
+func RolePage(ctx *iris.Context) {
+	ctx.JSON(iris.StatusOK, model.RoleAll())
+}
+
+/**
+* @api {post} /useradmin/role/permission get all permission
+* @apiName 获取所有可分配的权限
+* @apiGroup role
+* @apiVersion 1.0.0
+* @apiDescription 获取所有可分配的权限，key为权限标识，value为权限说明
+* @apiSampleRequest /useradmin/role/permission
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func RolePermission(ctx *iris.Context) {
+	ctx.JSON(iris.StatusOK, model.ApiJson{State: true, Msg: logic.Permissions})
+}
+
+/**
+* @api {post} /useradmin/role create role
+* @apiName 创建角色
+* @apiGroup role
+* @apiVersion 1.0.0
+* @apiDescription 创建角色，只能授予自己拥有的权限
+* @apiSampleRequest /useradmin/role
+* @apiParam {string} name 角色标识
+* @apiParam {string} title 角色名称
+* @apiParam {string} permissions 权限列表，逗号隔开
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 角色id
+* @apiPermission admin
+ */
+func RoleCreate(ctx *iris.Context) {
+	var role model.Role
+	if err := ctx.ReadJSON(&role); err != nil {
+		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
+	}
+	if err := validate.Struct(role); err != nil {
+		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
+	}
+	role.ID = 0
+	result := logic.RoleSave(role, currentUserAdmin(ctx).ID)
+	if id, ok := result.Msg.(int); ok && result.State {
+		auditLog(ctx, "role.create", []int{id}, nil, model.RoleList([]int{id}))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {PUT} /useradmin/role update role
+* @apiName 更新角色
+* @apiGroup role
+* @apiVersion 1.0.0
+* @apiDescription 更新角色，只能修改权限都是自己拥有的角色
+* @apiSampleRequest /useradmin/role
+* @apiParam {int} id 角色id
+* @apiParam {string} name 角色标识
+* @apiParam {string} title 角色名称
+* @apiParam {string} permissions 权限列表，逗号隔开
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func RoleUpdate(ctx *iris.Context) {
+	var role model.Role
+	if err := ctx.ReadJSON(&role); err != nil {
+		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
+	}
+	if err := validate.Struct(role); err != nil || role.ID == 0 {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ids := []int{role.ID}
+	before := model.RoleList(ids)
+	result := logic.RoleSave(role, currentUserAdmin(ctx).ID)
+	if result.State {
+		auditLog(ctx, "role.update", ids, before, model.RoleList(ids))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {delete} /useradmin/role delete role
+* @apiName 删除角色
+* @apiGroup role
+* @apiVersion 1.0.0
+* @apiDescription 删除角色，同时解除用户和该角色的关联，不能删除包含自己没有的权限的角色
+* @apiSampleRequest /useradmin/role
+* @apiParam {string} id 角色id，可传多个用逗号隔开
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func RoleDele(ctx *iris.Context) {
+	ids := ctx.FormValueString("id")
+	before := model.RoleList(Tools.ParseIds(ids))
+	result := logic.RoleDele(ids, currentUserAdmin(ctx).ID)
+	if result.State {
+		auditLog(ctx, "role.delete", Tools.ParseIds(ids), before, nil)
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {post} /useradmin/role/user get useradmin role
+* @apiName 获取用户的角色
+* @apiGroup role
+* @apiVersion 1.0.0
+* @apiDescription 获取用户的角色和权限
+* @apiSampleRequest /useradmin/role/user
+* @apiParam {int} uid 用户id
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {Role[]} --roles 角色列表
+* @apiSuccess {string[]} --permissions 权限列表
+* @apiPermission admin
+ */
+func UserRoleGet(ctx *iris.Context) {
+	uid := Tools.ParseInt(ctx.FormValueString("uid"), 0)
+	if err := validate.Var(uid, "required,min=1"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, model.ApiJson{State: true, Msg: map[string]interface{}{"roles": model.UserRoleGet(uid), "permissions": logic.UserAdminPermissions(uid)}})
+}
+
+/**
+* @api {post} /useradmin/role/assign assign useradmin role
+* @apiName 设置用户的角色
+* @apiGroup role
+* @apiVersion 1.0.0
+* @apiDescription 设置用户的角色，会覆盖原有的角色，受保护的账号不能修改，新旧角色的权限都必须是自己拥有的
+* @apiSampleRequest /useradmin/role/assign
+* @apiParam {int} uid 用户id
+* @apiParam {string} roleid 角色id，可传多个用逗号隔开，为空则清空角色
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func UserRoleAssign(ctx *iris.Context) {
+	uid := Tools.ParseInt(ctx.FormValueString("uid"), 0)
+	if err := validate.Var(uid, "required,min=1"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	before := model.UserRoleGet(uid)
+	result := logic.UserRoleSet(uid, ctx.FormValueString("roleid"), currentUserAdmin(ctx).ID)
+	if result.State {
+		auditLog(ctx, "role.assign", []int{uid}, before, model.UserRoleGet(uid))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
+	"pizzaCmsApi/model"
+)
+
+/**
+* @api {post} /search search article
+* @apiName 全文搜索文章
+* @apiGroup search
+* @apiVersion 1.0.0
+* @apiDescription 搜索文章的标题、标签、简介和正文，中文按字和相邻两字分词，按相关度排序。
+* 未登录时只搜索已发布的文章，带有效token时可以按审核状态筛选，有节点授权的管理员在授权节点以外只能搜到已发布的文章。修改文章后索引自动更新，也可以运行 -cmd=reindex 重建
+* @apiSampleRequest /search
+* @apiParam {string} kw 关键词
+* @apiParam {int} nodeid 节点id，包含子节点，可选
+* @apiParam {int} pass 审核状态，0或1，仅管理员可用，可选
+* @apiParam {int} start 创建时间起(unix时间戳)，可选
+* @apiParam {int} end 创建时间止(unix时间戳)，可选
+* @apiParam {int} cp cp
+* @apiParam {int} mp mp
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} count 总数
+* @apiSuccess {float} --score 相关度
+* @apiSuccess {string} --highlight 高亮的标题，关键词用<em>包起来
+* @apiSuccess {string} --snippet 高亮的正文片段
+ */
+func Search(ctx *iris.Context) {
+	kw := ctx.FormValueString("kw")
+	cp := Tools.ParseInt(ctx.FormValueString("cp"), 1)
+	mp := Tools.ParseInt(ctx.FormValueString("mp"), 20)
+	nodeid := Tools.ParseInt(ctx.FormValueString("nodeid"), 0)
+	pass := Tools.ParseInt(ctx.FormValueString("pass"), -1)
+	start := Tools.ParseInt(ctx.FormValueString("start"), 0)
+	end := Tools.ParseInt(ctx.FormValueString("end"), 0)
+	err1 := validate.Var(kw, "required,max=50")
+	err2 := validate.Var(cp, "required,min=1")
+	err3 := validate.Var(mp, "required,min=1,max=50")
+	err4 := validate.Var(pass, "min=-1,max=1")
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	user, admin := logic.SessionCheck(getToken(ctx))
+	if !admin {
+		pass = -1
+	}
+	ctx.JSON(iris.StatusOK, logic.Search(kw, nodeid, pass, int64(start), int64(end), cp, mp, !admin, user.ID))
+}
+
+/**
+* @api {post} /search/suggest search suggest
+* @apiName 搜索联想
+* @apiGroup search
+* @apiVersion 1.0.0
+* @apiDescription 输入时的自动补全，按前缀匹配文章标题和标签，也可以输入标题的拼音首字母，如bj匹配"北京"。
+* 关键词搜不到文章时，didyoumean返回相近的热门搜索词或标签。未登录时只包含已发布的文章
+* @apiSampleRequest /search/suggest
+* @apiParam {string} kw 关键词
+* @apiParam {int} limit 返回数量，默认10
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {object[]} --words 补全结果
+* @apiSuccess {string} ----word 标题或标签
+* @apiSuccess {string} ----kind title或tag
+* @apiSuccess {int} ----num 文章数
+* @apiSuccess {string[]} --didyoumean 纠正建议
+ */
+func SearchSuggest(ctx *iris.Context) {
+	kw := ctx.FormValueString("kw")
+	limit := Tools.ParseInt(ctx.FormValueString("limit"), 10)
+	err1 := validate.Var(kw, "required,max=50")
+	err2 := validate.Var(limit, "required,min=1,max=50")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	_, admin := logic.SessionCheck(getToken(ctx))
+	ctx.JSON(iris.StatusOK, logic.SearchSuggest(kw, limit, !admin))
+}
+
+/**
+* @api {post} /search/hot hot keywords
+* @apiName 热门搜索词
+* @apiGroup search
+* @apiVersion 1.0.0
+* @apiDescription 最近几天有结果的搜索词，按搜索次数降序
+* @apiSampleRequest /search/hot
+* @apiParam {int} days 统计天数，默认7，最多30
+* @apiParam {int} limit 返回数量，默认10
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {string} --keyword 搜索词
+* @apiSuccess {int} --score 搜索次数
+ */
+func SearchHot(ctx *iris.Context) {
+	days := Tools.ParseInt(ctx.FormValueString("days"), 7)
+	limit := Tools.ParseInt(ctx.FormValueString("limit"), 10)
+	err1 := validate.Var(days, "required,min=1,max=30")
+	err2 := validate.Var(limit, "required,min=1,max=50")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, model.ApiJson{State: true, Msg: logic.SearchHot(days, limit)})
+}
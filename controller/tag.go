@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
+	"pizzaCmsApi/model"
+)
+
+/**
+* @api {get} /tag/:name/articles tag articles
+* @apiName 获取某个标签的文章
+* @apiGroup tag
+* @apiVersion 1.0.0
+* @apiDescription 获取某个标签的文章，按id降序。未登录时只返回已发布的文章
+* @apiSampleRequest /tag/:name/articles
+* @apiParam {string} name 标签名称
+* @apiParam {int} cp cp
+* @apiParam {int} mp mp
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} count 总数
+ */
+func TagArticles(ctx *iris.Context) {
+	name := ctx.Param("name")
+	cp := Tools.ParseInt(ctx.FormValueString("cp"), 1)
+	mp := Tools.ParseInt(ctx.FormValueString("mp"), 20)
+	err1 := validate.Var(name, "required,max=30")
+	err2 := validate.Var(cp, "required,min=1")
+	err3 := validate.Var(mp, "required,min=1,max=50")
+	if err1 != nil || err2 != nil || err3 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	_, admin := logic.SessionCheck(getToken(ctx))
+	ctx.JSON(iris.StatusOK, logic.TagArticles(name, cp, mp, !admin))
+}
+
+/**
+* @api {post} /tag/cloud tag cloud
+* @apiName 标签云
+* @apiGroup tag
+* @apiVersion 1.0.0
+* @apiDescription 文章数最多的标签，未登录时只统计已发布的文章
+* @apiSampleRequest /tag/cloud
+* @apiParam {int} limit 返回数量，默认50
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} --id 标签id
+* @apiSuccess {string} --name 标签名称
+* @apiSuccess {int} --count 文章数
+* @apiSuccess {int} --level 1-5，文章数越多级别越高
+ */
+func TagCloud(ctx *iris.Context) {
+	limit := Tools.ParseInt(ctx.FormValueString("limit"), 50)
+	if err := validate.Var(limit, "required,min=1,max=200"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	_, admin := logic.SessionCheck(getToken(ctx))
+	ctx.JSON(iris.StatusOK, model.ApiJson{State: true, Msg: logic.TagCloud(limit, !admin)})
+}
+
+/**
+* @api {post} /tag/page page tag
+* @apiName 获取标签列表
+* @apiGroup tag
+* @apiVersion 1.0.0
+* @apiDescription 获取标签列表，按文章数降序
+* @apiSampleRequest /tag/page
+* @apiParam {string} kw 关键词
+* @apiParam {int} cp cp
+* @apiParam {int} mp mp
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} count 总数
+* @apiPermission admin
+ */
+func TagPage(ctx *iris.Context) {
+	kw := ctx.FormValueString("kw")
+	cp := Tools.ParseInt(ctx.FormValueString("cp"), 1)
+	mp := Tools.ParseInt(ctx.FormValueString("mp"), 20)
+	err1 := validate.Var(kw, "max=30")
+	err2 := validate.Var(cp, "required,min=1")
+	err3 := validate.Var(mp, "required,min=1,max=100")
+	if err1 != nil || err2 != nil || err3 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, model.TagPage(kw, cp, mp))
+}
+
+/**
+* @api {post} /tag create tag
+* @apiName 创建标签
+* @apiGroup tag
+* @apiVersion 1.0.0
+* @apiDescription 创建标签，保存文章时不存在的标签也会自动创建
+* @apiSampleRequest /tag
+* @apiParam {string} name 标签名称，不能包含空格和逗号
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 标签id
+* @apiPermission admin
+ */
+func TagCreate(ctx *iris.Context) {
+	var tag model.Tag
+	if err := ctx.ReadJSON(&tag); err != nil {
+		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
+	}
+	if err := validate.Struct(tag); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	result := logic.TagCreate(tag)
+	if id, ok := result.Msg.(int); ok && result.State {
+		auditLog(ctx, "tag.create", []int{id}, nil, model.TagList([]int{id}))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {PUT} /tag rename tag
+* @apiName 重命名标签
+* @apiGroup tag
+* @apiVersion 1.0.0
+* @apiDescription 重命名标签，使用该标签的文章同步修改。新名称已存在时请使用合并
+* @apiSampleRequest /tag
+* @apiParam {int} id 标签id
+* @apiParam {string} name 新名称
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func TagUpdate(ctx *iris.Context) {
+	id := Tools.ParseInt(ctx.FormValueString("id"), 0)
+	name := ctx.FormValueString("name")
+	err1 := validate.Var(id, "required,min=1")
+	err2 := validate.Var(name, "required,max=30")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ids := []int{id}
+	before := model.TagList(ids)
+	result := logic.TagRename(id, name)
+	if result.State {
+		auditLog(ctx, "tag.rename", ids, before, model.TagList(ids))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {delete} /tag delete tag
+* @apiName 删除标签
+* @apiGroup tag
+* @apiVersion 1.0.0
+* @apiDescription 删除标签，同时从文章中去掉该标签
+* @apiSampleRequest /tag
+* @apiParam {string} id 标签id，可传多个用逗号隔开
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func TagDele(ctx *iris.Context) {
+	ids := ctx.FormValueString("id")
+	before := model.TagList(Tools.ParseIds(ids))
+	result := logic.TagDele(ids)
+	if result.State {
+		auditLog(ctx, "tag.delete", Tools.ParseIds(ids), before, nil)
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {post} /tag/merge merge tag
+* @apiName 合并标签
+* @apiGroup tag
+* @apiVersion 1.0.0
+* @apiDescription 把多个标签合并到目标标签，原标签会被删除，文章的标签同步修改
+* @apiSampleRequest /tag/merge
+* @apiParam {string} id 被合并的标签id，可传多个用逗号隔开
+* @apiParam {int} to 目标标签id
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func TagMerge(ctx *iris.Context) {
+	ids := ctx.FormValueString("id")
+	to := Tools.ParseInt(ctx.FormValueString("to"), 0)
+	if err := validate.Var(to, "required,min=1"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	affected := append(Tools.ParseIds(ids), to)
+	before := model.TagList(affected)
+	result := logic.TagMerge(ids, to)
+	if result.State {
+		auditLog(ctx, "tag.merge", affected, before, model.TagList(affected))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
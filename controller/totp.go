@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
+)
+
+/**
+ * @api {post} /useradmin/login/totp useradmin login totp
+ * @apiName 两步验证登录
+ * @apiGroup useradmin
+ * @apiVersion 1.0.0
+ * @apiDescription 开启了两步验证的用户，/useradmin/login返回{"totp": true, "ticket": ticket}后调用此接口完成登录
+ * @apiSampleRequest /useradmin/login/totp
+ * @apiParam {string} ticket /useradmin/login返回的ticket
+ * @apiParam {string} code 身份验证器上的6位验证码，或者恢复码
+ * @apiSuccess {bool} state 状态
+ * @apiSuccess {String} msg 登录信息，同/useradmin/login
+ */
+func TotpLogin(ctx *iris.Context) {
+	ticket := ctx.FormValueString("ticket")
+	code := ctx.FormValueString("code")
+	err1 := validate.Var(ticket, "required,len=32")
+	err2 := validate.Var(code, "required,min=6,max=10")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.TotpLogin(ticket, code, clientIp(ctx)))
+}
+
+/**
+ * @api {post} /useradmin/totp/enroll enroll totp
+ * @apiName 绑定两步验证
+ * @apiGroup useradmin
+ * @apiVersion 1.0.0
+ * @apiDescription 生成两步验证密钥，前端用uri生成二维码，用户扫描后调用/useradmin/totp/enable确认，10分钟内有效
+ * @apiSampleRequest /useradmin/totp/enroll
+ * @apiSuccess {bool} state 状态
+ * @apiSuccess {String} msg 消息
+ * @apiSuccess {string} --secret 密钥
+ * @apiSuccess {string} --uri otpauth uri
+ * @apiPermission admin
+ */
+func TotpEnroll(ctx *iris.Context) {
+	ctx.JSON(iris.StatusOK, logic.TotpEnroll(currentUserAdmin(ctx)))
+}
+
+/**
+ * @api {post} /useradmin/totp/enable enable totp
+ * @apiName 开启两步验证
+ * @apiGroup useradmin
+ * @apiVersion 1.0.0
+ * @apiDescription 输入身份验证器上的验证码确认开启，返回的恢复码只显示这一次
+ * @apiSampleRequest /useradmin/totp/enable
+ * @apiParam {string} code 6位验证码
+ * @apiSuccess {bool} state 状态
+ * @apiSuccess {String} msg 消息
+ * @apiSuccess {string[]} --recovery 恢复码，每个只能使用一次
+ * @apiPermission admin
+ */
+func TotpEnable(ctx *iris.Context) {
+	code := ctx.FormValueString("code")
+	if err := validate.Var(code, "required,len=6"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	uid := currentUserAdmin(ctx).ID
+	result := logic.TotpEnable(uid, code)
+	if result.State {
+		//恢复码不写进日志
+		auditLog(ctx, "useradmin.totp.enable", []int{uid}, nil, nil)
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+ * @api {post} /useradmin/totp/disable disable totp
+ * @apiName 关闭两步验证
+ * @apiGroup useradmin
+ * @apiVersion 1.0.0
+ * @apiDescription 关闭自己的两步验证
+ * @apiSampleRequest /useradmin/totp/disable
+ * @apiParam {string} code 6位验证码，或者恢复码
+ * @apiSuccess {bool} state 状态
+ * @apiSuccess {String} msg 消息
+ * @apiPermission admin
+ */
+func TotpDisable(ctx *iris.Context) {
+	code := ctx.FormValueString("code")
+	if err := validate.Var(code, "required,min=6,max=10"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	uid := currentUserAdmin(ctx).ID
+	result := logic.TotpDisable(uid, code, clientIp(ctx))
+	if result.State {
+		auditLog(ctx, "useradmin.totp.disable", []int{uid}, nil, nil)
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+ * @api {post} /useradmin/totp/reset reset totp
+ * @apiName 重置两步验证
+ * @apiGroup useradmin
+ * @apiVersion 1.0.0
+ * @apiDescription 管理员为丢失设备的用户重置两步验证，重置后该用户需要重新登录。受保护的账号只能自己重置
+ * @apiSampleRequest /useradmin/totp/reset
+ * @apiParam {int} uid 用户id
+ * @apiSuccess {bool} state 状态
+ * @apiSuccess {String} msg 消息
+ * @apiPermission admin
+ */
+func TotpReset(ctx *iris.Context) {
+	uid := Tools.ParseInt(ctx.FormValueString("uid"), 0)
+	if err := validate.Var(uid, "required,min=1"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	result := logic.TotpReset(uid, currentUserAdmin(ctx).ID)
+	if result.State {
+		auditLog(ctx, "useradmin.totp.reset", []int{uid}, nil, nil)
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
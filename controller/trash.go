@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
+	"pizzaCmsApi/model"
+)
+
+/**
+* @api {post} /article/trash/page trash page
+* @apiName 回收站列表
+* @apiGroup article
+* @apiVersion 1.0.0
+* @apiDescription 回收站中的文章，按删除时间倒序，有节点授权时只返回授权节点下的文章
+* @apiSampleRequest /article/trash/page
+* @apiParam {string} kw 标题关键词
+* @apiParam {int} cp cp
+* @apiParam {int} mp mp
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} --trash_at 删除时间
+* @apiSuccess {int} --trash_by 删除人id
+* @apiSuccess {string} --trash_username 删除人用户名
+* @apiSuccess {int} count 总数
+* @apiPermission admin
+ */
+func ArticleTrashPage(ctx *iris.Context) {
+	kw := ctx.FormValueString("kw")
+	cp := Tools.ParseInt(ctx.FormValueString("cp"), 1)
+	mp := Tools.ParseInt(ctx.FormValueString("mp"), 20)
+	err1 := validate.Var(kw, "max=50")
+	err2 := validate.Var(cp, "required,min=1")
+	err3 := validate.Var(mp, "required,min=1,max=100")
+	if err1 != nil || err2 != nil || err3 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.ArticleTrashPage(kw, cp, mp, currentUserAdmin(ctx)))
+}
+
+/**
+* @api {post} /article/trash/restore restore article
+* @apiName 恢复文章
+* @apiGroup article
+* @apiVersion 1.0.0
+* @apiDescription 从回收站恢复文章，恢复后保持删除前的状态
+* @apiSampleRequest /article/trash/restore
+* @apiParam {string} id 文章id，可传多个用逗号隔开
+* @apiSuccess {bool} state 状态
+* @apiSuccess {int[]} msg 恢复的文章id
+* @apiPermission admin
+ */
+func ArticleRestore(ctx *iris.Context) {
+	ids := ctx.FormValueString("id")
+	err1 := validate.Var(ids, "required")
+	if err1 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	result := logic.ArticleRestore(ids, currentUserAdmin(ctx))
+	if restored, ok := result.Msg.([]int); ok && len(restored) > 0 {
+		auditLog(ctx, "article.restore", restored, nil, nil)
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {post} /article/trash/purge purge article
+* @apiName 彻底删除文章
+* @apiGroup article
+* @apiVersion 1.0.0
+* @apiDescription 彻底删除回收站中的文章，文章的评论和历史版本一起删除，不能恢复。不在回收站的文章会被忽略
+* @apiSampleRequest /article/trash/purge
+* @apiParam {string} id 文章id，可传多个用逗号隔开
+* @apiSuccess {bool} state 状态
+* @apiSuccess {int[]} msg 删除的文章id
+* @apiPermission admin
+ */
+func ArticlePurge(ctx *iris.Context) {
+	ids := ctx.FormValueString("id")
+	err1 := validate.Var(ids, "required")
+	if err1 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	before := model.ArticleList(Tools.ParseIds(ids))
+	result := logic.ArticlePurge(ids, currentUserAdmin(ctx))
+	if purged, ok := result.Msg.([]int); ok && len(purged) > 0 {
+		auditLog(ctx, "article.purge", purged, before, nil)
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
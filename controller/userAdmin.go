@@ -5,7 +5,6 @@ import (
 	"github.com/kataras/iris"
 	"pizzaCmsApi/logic"
 	"pizzaCmsApi/model"
-	"strings"
 )
 
 /**
@@ -34,17 +33,78 @@ func UserAdminGet(ctx *iris.Context) {
  * @apiParam {string} username username
  * @apiParam {string} password password
  * @apiSuccess {bool} state 状态
- * @apiSuccess {String} msg 用户信息
+ * @apiSuccess {String} msg 登录信息，失败次数过多被锁定时为{"msg": "account is locked", "locked": true, "retry": 剩余锁定秒数}
+ * @apiSuccess {string} --token 会话token，后续请求放在header Authorization: Bearer <token>
+ * @apiSuccess {int} --expire token有效期，单位秒
+ * @apiSuccess {UserAdmin} --user 用户信息
+ * @apiSuccess {bool} --totp 开启了两步验证时为true，此时只返回ticket，需要再调用/useradmin/login/totp
+ * @apiSuccess {string} --ticket 两步验证的ticket，5分钟内有效
  */
 func UserAdminCheckLogin(ctx *iris.Context) {
-	username := ctx.Param("username")
-	password := ctx.Param("password")
+	username := ctx.FormValueString("username")
+	password := ctx.FormValueString("password")
 	err1 := validate.Var(username, "required,min=4,max=20")
 	err2 := validate.Var(password, "required,min=6,max=20")
 	if err1 != nil || err2 != nil {
 		ctx.JSON(iris.StatusOK, errorValidate())
+		return
 	}
-	ctx.JSON(iris.StatusOK, logic.UserAdminCheckLogin(username, password))
+	ctx.JSON(iris.StatusOK, logic.UserAdminCheckLogin(username, password, clientIp(ctx)))
+}
+
+/**
+ * @api {post} /useradmin/logout useradmin logout
+ * @apiName 退出登录
+ * @apiGroup useradmin
+ * @apiVersion 1.0.0
+ * @apiDescription 注销当前token
+ * @apiSampleRequest /useradmin/logout
+ * @apiSuccess {bool} state 状态
+ * @apiSuccess {String} msg 消息
+ * @apiPermission admin
+ */
+func UserAdminLogout(ctx *iris.Context) {
+	ctx.JSON(iris.StatusOK, logic.SessionDestroy(ctx.GetString("token")))
+}
+
+/**
+ * @api {post} /useradmin/refresh useradmin refresh token
+ * @apiName 刷新token
+ * @apiGroup useradmin
+ * @apiVersion 1.0.0
+ * @apiDescription 用当前token换取新的token，旧token立即失效
+ * @apiSampleRequest /useradmin/refresh
+ * @apiSuccess {bool} state 状态
+ * @apiSuccess {String} msg 消息
+ * @apiSuccess {string} --token 新的token
+ * @apiSuccess {int} --expire token有效期，单位秒
+ * @apiPermission admin
+ */
+func UserAdminRefresh(ctx *iris.Context) {
+	ctx.JSON(iris.StatusOK, logic.SessionRefresh(ctx.GetString("token")))
+}
+
+/**
+* @api {post} /useradmin/unlock unlock useradmin
+* @apiName 解除登录锁定
+* @apiGroup useradmin
+* @apiVersion 1.0.0
+* @apiDescription 解除因登录失败次数过多导致的锁定，username和ip至少传一个
+* @apiSampleRequest /useradmin/unlock
+* @apiParam {string} username 用户名
+* @apiParam {string} ip ip地址
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func UserAdminUnlock(ctx *iris.Context) {
+	username := ctx.FormValueString("username")
+	ip := ctx.FormValueString("ip")
+	result := logic.LoginUnlock(username, ip)
+	if result.State {
+		auditLog(ctx, "useradmin.unlock", nil, nil, map[string]string{"username": username, "ip": ip})
+	}
+	ctx.JSON(iris.StatusOK, result)
 }
 
 /**
@@ -68,7 +128,7 @@ func UserAdminGetByPath(ctx *iris.Context) {
 * @apiName 更新useradmin信息
 * @apiGroup useradmin
 * @apiVersion 1.0.0
-* @apiDescription 后台管理员更新用户信息，如果传入密码，则更新密码，如果不传，则不更新
+* @apiDescription 后台管理员更新用户信息，如果传入密码，则更新密码并注销该用户的全部会话，如果不传，则不更新。受保护的账号只能自己修改，修改其他人时需要拥有对方的全部权限
 * @apiSampleRequest /useradmin
 * @apiParam {string} username 用户名
 * @apiParam {string} nickname 昵称
@@ -85,10 +145,22 @@ func UserAdminUpdate(ctx *iris.Context) {
 		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
 	} else {
 		err1 := validate.Struct(user)
+		err2 := validate.Var(user.ID, "required,min=1")
 		if err1 != nil {
 			ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err1.Error()})
+			return
+		}
+		if err2 != nil {
+			ctx.JSON(iris.StatusOK, errorValidate())
+			return
 		}
-		ctx.JSON(iris.StatusOK, model.UserAdminUpdate(user))
+		ids := []int{user.ID}
+		before := model.UserAdminList(ids)
+		result := logic.UserAdminUpdate(user, currentUserAdmin(ctx).ID)
+		if result.State {
+			auditLog(ctx, "useradmin.update", ids, before, model.UserAdminList(ids))
+		}
+		ctx.JSON(iris.StatusOK, result)
 	}
 
 }
@@ -110,12 +182,18 @@ func UserAdminCreate(ctx *iris.Context) {
 	err := ctx.ReadJSON(&user)
 	if err != nil {
 		ctx.JSON(iris.StatusOK, model.ApiJson{State: false, Msg: err.Error()})
+		return
 	}
 	err1 := validate.Struct(user)
 	if err1 != nil {
 		ctx.JSON(iris.StatusOK, `{"state": false, "msg": `+err1.Error()+`}`)
+		return
+	}
+	result := model.UserAdminCreate(user)
+	if id, ok := result.Msg.(int); ok && result.State {
+		auditLog(ctx, "useradmin.create", []int{id}, nil, model.UserAdminList([]int{id}))
 	}
-	ctx.JSON(iris.StatusOK, model.UserAdminCreate(user))
+	ctx.JSON(iris.StatusOK, result)
 }
 
 /**
@@ -133,9 +211,9 @@ func UserAdminCreate(ctx *iris.Context) {
 * @apiPermission admin
  */
 func UserAdminPage(ctx *iris.Context) {
-	cp := Tools.ParseInt(ctx.Param("cp"), 1)
-	mp := Tools.ParseInt(ctx.Param("mp"), 20)
-	kw := ctx.Param("kw")
+	cp := Tools.ParseInt(ctx.FormValueString("cp"), 1)
+	mp := Tools.ParseInt(ctx.FormValueString("mp"), 20)
+	kw := ctx.FormValueString("kw")
 
 	ctx.JSON(iris.StatusOK, model.UserAdminPage(kw, cp, mp))
 }
@@ -145,7 +223,7 @@ func UserAdminPage(ctx *iris.Context) {
 * @apiName delete useradmin
 * @apiGroup useradmin
 * @apiVersion 1.0.0
-* @apiDescription delete useradmin by ids[]
+* @apiDescription delete useradmin by ids[]，受保护的账号(config.toml中rbac.protected)和拥有自己没有的权限的账号不能删除
 * @apiSampleRequest /useradmin
 * @apiParam {string} id 用户id
 * @apiSuccess {bool} state 状态
@@ -153,10 +231,11 @@ func UserAdminPage(ctx *iris.Context) {
 * @apiPermission admin
  */
 func UserAdminDele(ctx *iris.Context) {
-	ids := ctx.Param("id")
-	if strings.Index(","+ids+",", ",1,") == -1 {
-		ctx.JSON(iris.StatusOK, logic.UserAdminDele(ids))
-	} else {
-		ctx.JSON(iris.StatusOK, `{"state": false, "msg": "root is not del"}`)
+	ids := ctx.FormValueString("id")
+	before := model.UserAdminList(Tools.ParseIds(ids))
+	result := logic.UserAdminDele(ids, currentUserAdmin(ctx).ID)
+	if result.State {
+		auditLog(ctx, "useradmin.delete", Tools.ParseIds(ids), before, nil)
 	}
+	ctx.JSON(iris.StatusOK, result)
 }
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
+	"pizzaCmsApi/model"
+)
+
+/**
+* @api {post} /useradmin/node get useradmin node
+* @apiName 获取用户的节点授权
+* @apiGroup role
+* @apiVersion 1.0.0
+* @apiDescription 获取授权给用户的节点，all为true时不限节点，否则只能管理nodes中的节点
+* @apiSampleRequest /useradmin/node
+* @apiParam {int} uid 用户id
+* @apiSuccess {bool} state 状态
+* @apiSuccess {bool} msg.all 是否不限节点
+* @apiSuccess {Node[]} msg.nodes 节点列表
+* @apiPermission admin
+ */
+func UserNodeGet(ctx *iris.Context) {
+	uid := Tools.ParseInt(ctx.FormValueString("uid"), 0)
+	if err := validate.Var(uid, "required,min=1"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	nodes := model.UserNodeGet(uid)
+	ctx.JSON(iris.StatusOK, model.ApiJson{State: true, Msg: map[string]interface{}{"all": model.UserNodeAll(uid), "nodes": nodes}, Count: len(nodes)})
+}
+
+/**
+* @api {post} /useradmin/node/assign assign useradmin node
+* @apiName 设置用户的节点授权
+* @apiGroup role
+* @apiVersion 1.0.0
+* @apiDescription 设置用户可以管理的节点，授权包含所有下级节点，会覆盖原有的授权。限制节点的用户只能查看、创建、修改、删除、审核授权节点下的文章，
+* 文章列表、版本和待审核列表也只返回这些文章，没有授权时不能管理任何文章。限制节点的操作人只能在自己的授权范围内分配，不能设置不限节点
+* @apiSampleRequest /useradmin/node/assign
+* @apiParam {int} uid 用户id
+* @apiParam {int} all 为1时不限节点，忽略nodeid
+* @apiParam {string} nodeid 节点id，可传多个用逗号隔开
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func UserNodeAssign(ctx *iris.Context) {
+	uid := Tools.ParseInt(ctx.FormValueString("uid"), 0)
+	if err := validate.Var(uid, "required,min=1"); err != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	before := model.UserNodeGet(uid)
+	result := logic.UserNodeSet(uid, ctx.FormValueString("nodeid"), ctx.FormValueString("all") == "1", currentUserAdmin(ctx).ID)
+	if result.State {
+		auditLog(ctx, "node.assign", []int{uid}, before, model.UserNodeGet(uid))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"github.com/kataras/iris"
+	"pizzaCmsApi/logic"
+	"pizzaCmsApi/model"
+)
+
+/**
+* @api {post} /article/workflow change article status
+* @apiName 修改文章的编辑流程状态
+* @apiGroup workflow
+* @apiVersion 1.0.0
+* @apiDescription 状态流转：draft→review→approved→published→archived，review→rejected→review。
+* 提交审核、撤回需要article.edit权限，审核、发布、归档需要article.pass权限。pass字段随状态同步，published时为1
+* @apiSampleRequest /article/workflow
+* @apiParam {int} id 文章id
+* @apiParam {string} status 目标状态：draft、review、approved、published、archived、rejected
+* @apiParam {string} note 审核意见，rejected时必填
+* @apiParam {int} reviewer 提交审核时指定审核人，可选
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func ArticleWorkflow(ctx *iris.Context) {
+	id := Tools.ParseInt(ctx.FormValueString("id"), 0)
+	status := ctx.FormValueString("status")
+	note := ctx.FormValueString("note")
+	reviewer := Tools.ParseInt(ctx.FormValueString("reviewer"), 0)
+	err1 := validate.Var(id, "required,min=1")
+	err2 := validate.Var(status, "required,max=20")
+	err3 := validate.Var(note, "max=255")
+	if err1 != nil || err2 != nil || err3 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ids := []int{id}
+	before := model.ArticleList(ids)
+	result := logic.ArticleTransition(id, status, note, reviewer, currentUserAdmin(ctx))
+	if result.State {
+		auditLog(ctx, "article.workflow."+status, ids, before, model.ArticleList(ids))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {post} /article/workflow/assign assign reviewer
+* @apiName 指定文章的审核人
+* @apiGroup workflow
+* @apiVersion 1.0.0
+* @apiDescription 指定待审核文章的审核人，审核人需要有article.pass权限和文章所在节点的权限
+* @apiSampleRequest /article/workflow/assign
+* @apiParam {int} id 文章id
+* @apiParam {int} reviewer 审核人id
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiPermission admin
+ */
+func ArticleAssignReviewer(ctx *iris.Context) {
+	id := Tools.ParseInt(ctx.FormValueString("id"), 0)
+	reviewer := Tools.ParseInt(ctx.FormValueString("reviewer"), 0)
+	err1 := validate.Var(id, "required,min=1")
+	err2 := validate.Var(reviewer, "required,min=1")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ids := []int{id}
+	before := model.ArticleList(ids)
+	result := logic.ArticleAssignReviewer(id, reviewer, currentUserAdmin(ctx))
+	if result.State {
+		auditLog(ctx, "article.workflow.assign", ids, before, model.ArticleList(ids))
+	}
+	ctx.JSON(iris.StatusOK, result)
+}
+
+/**
+* @api {post} /article/workflow/queue my review queue
+* @apiName 我的待审核文章
+* @apiGroup workflow
+* @apiVersion 1.0.0
+* @apiDescription 获取指定给当前管理员审核的文章，只包含授权节点下的文章
+* @apiSampleRequest /article/workflow/queue
+* @apiParam {int} all 为1时同时返回未指定审核人的文章
+* @apiParam {int} cp cp
+* @apiParam {int} mp mp
+* @apiSuccess {bool} state 状态
+* @apiSuccess {String} msg 消息
+* @apiSuccess {int} count 总数
+* @apiPermission admin
+ */
+func ArticleReviewQueue(ctx *iris.Context) {
+	cp := Tools.ParseInt(ctx.FormValueString("cp"), 1)
+	mp := Tools.ParseInt(ctx.FormValueString("mp"), 20)
+	all := ctx.FormValueString("all") == "1"
+	err1 := validate.Var(cp, "required,min=1")
+	err2 := validate.Var(mp, "required,min=1,max=50")
+	if err1 != nil || err2 != nil {
+		ctx.JSON(iris.StatusOK, errorValidate())
+		return
+	}
+	ctx.JSON(iris.StatusOK, logic.ArticleReviewQueue(currentUserAdmin(ctx), all, cp, mp))
+}
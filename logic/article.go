@@ -6,39 +6,181 @@ import (
 )
 
 /**
- * 删除文章
- * @method UserDele
- * @param  {[type]} ids string [description]
+ * 创建文章，作者为当前管理员，同时保存第一个版本
+ * @method ArticleCreate
+ * @param  {[type]} article model.Article   [description]
+ * @param  {[type]} user    model.UserAdmin 操作人
  */
-func ArticleDele(ids string) model.ApiJson {
-	idsArr := strings.Split(ids, ",")
-	length := len(idsArr)
-	if length > 0 {
-		var idsInt = make([]int, length, length)
-		for i, id := range idsArr {
-			idsInt[i] = Tools.ParseInt(id, 0)
-		}
-		return model.ArticleDele(idsInt)
-	} else {
+func ArticleCreate(article model.Article, user model.UserAdmin) model.ApiJson {
+	if !NodeAllowed(user.ID, article.Nodeid) {
+		return model.ApiJson{State: false, Msg: "permission denied"}
+	}
+	article.Uid = user.ID
+	if msg := articleSchedule(article); msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	article, msg := articleContent(article)
+	if msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	article = articleSummary(article)
+	if article.Slug == "" {
+		article.Slug = SlugGenerate(article.Title, 0)
+	} else if msg := SlugCheck(article.Slug, 0); msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	result := model.ArticleCreate(article)
+	if id, ok := result.Msg.(int); ok && result.State {
+		article.ID = id
+		if err := ArticleTagSave(id, article.Tags); err != nil {
+			Tools.Logs("article tag " + Tools.ParseString(id) + ": " + err.Error())
+		}
+		revisionSave(article, user)
+		SearchSync([]int{id})
+		RankSync([]int{id})
+		CacheInvalidateArticles([]int{id})
+	}
+	return result
+}
+
+/**
+ * 更新文章，每次更新都保存一个版本，slug为空时保持不变，修改后旧的slug会跳转到新的
+ * 没有审核权限时不能修改已发布的文章，修改审核通过的文章后文章回到待审核
+ * @method ArticleUpdate
+ * @param  {[type]} article model.Article   [description]
+ * @param  {[type]} user    model.UserAdmin 操作人
+ */
+func ArticleUpdate(article model.Article, user model.UserAdmin) model.ApiJson {
+	if article.ID < 1 {
 		return model.ApiJson{State: false, Msg: "id is error"}
 	}
+	current := model.ArticleList([]int{article.ID})
+	if len(current) == 0 {
+		return model.ApiJson{State: false, Msg: "article is no exist"}
+	}
+	if current[0].TrashAt > 0 {
+		return model.ApiJson{State: false, Msg: "article is in trash"}
+	}
+	//原节点和新节点都需要有权限
+	if !ArticleAllowed(user.ID, []int{article.ID}) || !NodeAllowed(user.ID, article.Nodeid) {
+		return model.ApiJson{State: false, Msg: "permission denied"}
+	}
+	//已发布的内容直接修改线上文章，需要审核权限，只有编辑权限时先撤回再提交审核
+	reviewer := HasPermission(user.ID, PermArticlePass)
+	if current[0].Status == model.ArticlePublished && !reviewer {
+		return model.ApiJson{State: false, Msg: "published article needs review permission to change"}
+	}
+	if msg := articleSchedule(article); msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	article, msg := articleContent(article)
+	if msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	article = articleSummary(article)
+	if article.Slug != "" {
+		if msg := SlugCheck(article.Slug, article.ID); msg != "" {
+			return model.ApiJson{State: false, Msg: msg}
+		}
+	}
+	if model.RevisionCount(article.ID) == 0 { //旧文章没有历史版本，先保存修改前的内容
+		revisionSave(current[0], model.UserAdminFind(current[0].Uid))
+	}
+	result := model.ArticleUpdate(article)
+	if result.State {
+		//审核通过等待发布的文章被没有审核权限的人修改后重新审核，否则修改的内容会不经审核直接发布
+		if current[0].Status == model.ArticleApproved && !reviewer {
+			if err := model.ArticleSetStatus(article.ID, model.ArticleApproved, model.ArticleReview, map[string]interface{}{"review_note": ""}); err != nil {
+				Tools.Logs("article review " + Tools.ParseString(article.ID) + ": " + err.Error())
+			}
+		}
+		if article.Slug != "" {
+			if err := model.ArticleSlugSet(article.ID, article.Slug); err != nil {
+				return model.ApiJson{State: false, Msg: err.Error()}
+			}
+		}
+		if err := ArticleTagSave(article.ID, article.Tags); err != nil {
+			Tools.Logs("article tag " + Tools.ParseString(article.ID) + ": " + err.Error())
+		}
+		if current := model.ArticleList([]int{article.ID}); len(current) > 0 {
+			revisionSave(current[0], user)
+		}
+		SearchSync([]int{article.ID})
+		RankSync([]int{article.ID})
+		CacheInvalidateArticles([]int{article.ID})
+	}
+	return result
+}
+
+/**
+ * 删除文章，文章移到回收站，可以恢复，超过保留时间后自动彻底删除
+ * @method ArticleDele
+ * @param  {[type]} ids  string          [description]
+ * @param  {[type]} user model.UserAdmin 操作人
+ */
+func ArticleDele(ids string, user model.UserAdmin) model.ApiJson {
+	idsInt := Tools.ParseIds(ids)
+	if len(idsInt) == 0 {
+		return model.ApiJson{State: false, Msg: "id is error"}
+	}
+	if !ArticleAllowed(user.ID, idsInt) {
+		return model.ApiJson{State: false, Msg: "permission denied"}
+	}
+	trashed, err := model.ArticleTrash(idsInt, user.ID)
+	if err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	if len(trashed) > 0 {
+		SearchRemove(trashed)
+		CacheInvalidateArticles(trashed)
+	}
+	return model.ApiJson{State: true, Msg: trashed, Count: len(trashed)}
 }
 
 /**
- * 审核文章
- * @method UserDele
- * @param  {[type]} ids string [description]
+ * 审核文章，兼容旧接口，按编辑流程修改状态：pass=1时发布，待审核的文章先通过审核，已发布的文章pass=0时归档
+ * @method ArticlePass
+ * @param  {[type]} ids  string          [description]
+ * @param  {[type]} pass int             [description]
+ * @param  {[type]} user model.UserAdmin 操作人
  */
-func ArticlePass(ids string, pass int) model.ApiJson {
-	idsArr := strings.Split(ids, ",")
-	length := len(idsArr)
-	if length > 0 {
-		var idsInt = make([]int, length, length)
-		for i, id := range idsArr {
-			idsInt[i] = Tools.ParseInt(id, 0)
-		}
-		return model.ArticlePass(idsInt, pass)
-	} else {
+func ArticlePass(ids string, pass int, user model.UserAdmin) model.ApiJson {
+	idsInt := Tools.ParseIds(ids)
+	if len(idsInt) == 0 {
 		return model.ApiJson{State: false, Msg: "id is error"}
 	}
+	if !ArticleAllowed(user.ID, idsInt) {
+		return model.ApiJson{State: false, Msg: "permission denied"}
+	}
+	var failed []string
+	for _, article := range model.ArticleList(idsInt) {
+		var steps []string
+		if pass == 1 && article.Status != model.ArticlePublished {
+			if article.Status == model.ArticleReview {
+				steps = append(steps, model.ArticleApproved)
+			}
+			steps = append(steps, model.ArticlePublished)
+		} else if pass != 1 && article.Status == model.ArticlePublished {
+			steps = append(steps, model.ArticleArchived)
+		}
+		for _, to := range steps {
+			if result := ArticleTransition(article.ID, to, "", 0, user); !result.State {
+				failed = append(failed, Tools.ParseString(article.ID)+": "+result.Msg.(string))
+				break
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return model.ApiJson{State: false, Msg: strings.Join(failed, "; ")}
+	}
+	return model.ApiJson{State: true}
+}
+
+//////////私有方法
+//检查定时发布和自动下线时间，两个都设置时下线时间必须晚于发布时间
+func articleSchedule(article model.Article) string {
+	if article.PublishAt > 0 && article.ExpireAt > 0 && article.ExpireAt <= article.PublishAt {
+		return "expire_at must be later than publish_at"
+	}
+	return ""
 }
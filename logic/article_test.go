@@ -0,0 +1,16 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+	"testing"
+)
+
+//没有id时必须在访问数据库之前拒绝，否则gorm不加where条件会更新所有文章
+func TestArticleUpdateRequiresID(t *testing.T) {
+	for _, id := range []int{0, -1} {
+		article := model.Article{ID: id, Title: "title", Content: "content", Nodeid: 1}
+		if result := ArticleUpdate(article, model.UserAdmin{ID: 1}); result.State {
+			t.Errorf("ArticleUpdate() with id %d succeeded", id)
+		}
+	}
+}
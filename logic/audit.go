@@ -0,0 +1,70 @@
+package logic
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"pizzaCmsApi/model"
+	"time"
+)
+
+/**
+ * 记录审计日志，异步写入mongodb，写入失败只打印日志不影响业务
+ * @method AuditLog
+ * @param  {[type]} user   model.UserAdmin 操作人
+ * @param  {[type]} ip     string          客户端ip
+ * @param  {[type]} action string          操作，如article.update
+ * @param  {[type]} ids    []int           操作对象的id
+ * @param  {[type]} before interface{}     操作前的数据
+ * @param  {[type]} after  interface{}     操作后的数据
+ */
+func AuditLog(user model.UserAdmin, ip string, action string, ids []int, before interface{}, after interface{}) {
+	audit := model.Audit{
+		Uid:      user.ID,
+		Username: user.Username,
+		Action:   action,
+		Ids:      ids,
+		Before:   before,
+		After:    after,
+		Ip:       ip,
+		Time:     time.Now().Unix(),
+	}
+	go func() {
+		if err := model.AuditCreate(audit); err != nil {
+			Tools.Logs("audit log error: " + err.Error())
+		}
+	}()
+}
+
+/**
+ * 查询审计日志
+ * @method AuditPage
+ * @param  {[type]} uid    int    操作人id，0表示不限
+ * @param  {[type]} action string 操作，为空表示不限
+ * @param  {[type]} id     int    操作对象id，0表示不限
+ * @param  {[type]} start  int64  开始时间，0表示不限
+ * @param  {[type]} end    int64  结束时间，0表示不限
+ * @param  {[type]} cp     int    [description]
+ * @param  {[type]} mp     int    [description]
+ */
+func AuditPage(uid int, action string, id int, start int64, end int64, cp int, mp int) model.ApiJson {
+	query := bson.M{}
+	if uid > 0 {
+		query["uid"] = uid
+	}
+	if action != "" {
+		query["action"] = action
+	}
+	if id > 0 {
+		query["ids"] = id
+	}
+	if start > 0 || end > 0 {
+		t := bson.M{}
+		if start > 0 {
+			t["$gte"] = start
+		}
+		if end > 0 {
+			t["$lte"] = end
+		}
+		query["time"] = t
+	}
+	return model.AuditPage(query, cp, mp)
+}
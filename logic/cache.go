@@ -0,0 +1,126 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+)
+
+//缓存标签
+const (
+	CacheTagArticle = "article:"     //单篇文章，article:文章id
+	CacheTagList    = "article:list" //文章列表
+	CacheTagNode    = "node"         //包含节点信息的数据
+	CacheTagMenu    = "menu"         //导航菜单
+)
+
+const cachePageMax = 20 //列表只缓存前几页，更深的页直接查询，避免任意页码让缓存无限增长
+
+type articlePageCache struct {
+	Articles []model.ArticleResults
+	Count    int
+}
+
+/**
+ * 前台读取文章，带缓存，节点修改(如隐藏)时同时清除
+ * @method ArticleGetPublic
+ * @param  {[type]} id int [description]
+ */
+func ArticleGetPublic(id int) model.ApiJson {
+	var article model.Article
+	err := Redis.Remember("article", "article:public:"+Tools.ParseString(id), cacheTTL(Config.Cache.Article), []string{CacheTagArticle + Tools.ParseString(id), CacheTagNode}, &article, func() (interface{}, error) {
+		result := model.ArticleGetPublic(id)
+		if !result.State {
+			return model.Article{}, nil //不存在的文章也缓存，避免穿透
+		}
+		return result.Msg, nil
+	})
+	if err != nil {
+		return model.ArticleGetPublic(id)
+	}
+	if article.ID == 0 {
+		return model.ApiJson{State: false, Msg: "article is no exist"}
+	}
+	return model.ApiJson{State: true, Msg: article}
+}
+
+/**
+ * 文章列表，带缓存，任何文章修改都会清除列表缓存，按节点设置排序，前台不显示隐藏的节点
+ * 带关键词的查询、超过cachePageMax的页和不存在的节点不缓存，避免任意参数让缓存和标签集合无限增长
+ * @method ArticlePage
+ * @param  {[type]} kw     string [description]
+ * @param  {[type]} nodeid int    [description]
+ * @param  {[type]} cp     int    [description]
+ * @param  {[type]} mp     int    每页数量，0表示使用节点设置
+ * @param  {[type]} public bool   [description]
+ */
+func ArticlePage(kw string, nodeid int, cp int, mp int, public bool) model.ApiJson {
+	setting := NodeSettingGet(nodeid)
+	if public && setting.Hidden {
+		return model.ApiJson{State: false, Msg: "node is no exist"}
+	}
+	if mp <= 0 {
+		mp = setting.Pagesize
+	}
+	order := model.NodeSorts[setting.Sort]
+	if kw != "" || cp > cachePageMax || (nodeid > 0 && setting.Nodeid == 0) {
+		return model.ArticlePage(kw, nodeid, cp, mp, public, order)
+	}
+	var page articlePageCache
+	key := "article:page:" + Tools.ParseString(nodeid) + ":" + Tools.ParseString(cp) + ":" + Tools.ParseString(mp) + ":" + setting.Sort
+	if public {
+		key += ":public"
+	}
+	err := Redis.Remember("article.page", key, cacheTTL(Config.Cache.List), []string{CacheTagList, CacheTagNode}, &page, func() (interface{}, error) {
+		result := model.ArticlePage(kw, nodeid, cp, mp, public, order)
+		return articlePageCache{Articles: result.Msg.([]model.ArticleResults), Count: result.Count}, nil
+	})
+	if err != nil {
+		return model.ArticlePage(kw, nodeid, cp, mp, public, order)
+	}
+	if page.Articles == nil {
+		page.Articles = []model.ArticleResults{}
+	}
+	return model.ApiJson{State: true, Msg: page.Articles, Count: page.Count}
+}
+
+/**
+ * 文章修改后清除文章和列表的缓存
+ * @method CacheInvalidateArticles
+ * @param  {[type]} ids []int [description]
+ */
+func CacheInvalidateArticles(ids []int) {
+	tags := []string{CacheTagList}
+	for _, id := range ids {
+		tags = append(tags, CacheTagArticle+Tools.ParseString(id))
+	}
+	Redis.Invalidate(tags...)
+}
+
+/**
+ * 缓存命中统计
+ * @method CacheStats
+ */
+func CacheStats() model.ApiJson {
+	return model.ApiJson{State: true, Msg: Redis.CacheStats()}
+}
+
+/**
+ * 清除缓存
+ * @method CacheClear
+ * @param  {[type]} tags  []string 缓存标签
+ * @param  {[type]} reset bool     是否同时清空命中统计
+ */
+func CacheClear(tags []string, reset bool) model.ApiJson {
+	Redis.Invalidate(tags...)
+	if reset {
+		Redis.CacheStatsReset()
+	}
+	return model.ApiJson{State: true}
+}
+
+//////////私有方法
+func cacheTTL(ttl int) int {
+	if ttl <= 0 {
+		return 60
+	}
+	return ttl
+}
@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"errors"
+)
+
+//命令行任务，通过 -cmd=名称 运行
+var commands = map[string]func() error{
+	"reindex":  SearchReindex,   //重建搜索索引
+	"tags":     TagMigrate,      //把文章的tags字段拆分到tag表
+	"slugs":    SlugMigrate,     //给没有slug或占位slug的文章生成slug
+	"rank":     RankRebuild,     //从数据库重建排行榜
+	"sanitize": SanitizeMigrate, //按当前规则过滤已有的文章和评论内容
+	"summary":  SummaryMigrate,  //计算已有文章的字数和阅读时间，生成空摘要
+}
+
+/**
+ * 运行命令行任务
+ * @method Command
+ * @param  {[type]} name string [description]
+ */
+func Command(name string) error {
+	cmd, ok := commands[name]
+	if !ok {
+		return errors.New("unknown command " + name)
+	}
+	return cmd()
+}
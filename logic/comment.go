@@ -0,0 +1,136 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+	"strings"
+	"time"
+)
+
+/**
+ * 创建评论，内容按白名单过滤，按文章所在节点的设置关闭评论或需要审核
+ * 前台读者也可以评论，只能评论前台可见的文章
+ * @method CommentCreate
+ * @param  {[type]} comment model.Comment   [description]
+ * @param  {[type]} user    model.UserAdmin 当前登录的管理员，未登录时为空
+ */
+func CommentCreate(comment model.Comment, user model.UserAdmin) model.ApiJson {
+	comment = commentAuthor(comment, user)
+	//未发布、已下线和回收站中的文章不能评论
+	articles := model.ArticleList([]int{comment.Articleid})
+	if len(articles) == 0 || !model.ArticleGetPublic(comment.Articleid).State {
+		return model.ApiJson{State: false, Msg: "article is no exist"}
+	}
+	//只能新建评论，不能通过id覆盖已有的评论
+	comment.Id = 0
+	comment.Addtime = int(time.Now().Unix())
+	//按文章所在节点的评论策略处理
+	setting := NodeSettingGet(articles[0].Nodeid)
+	if setting.Hidden {
+		return model.ApiJson{State: false, Msg: "article is no exist"}
+	}
+	switch setting.Comment {
+	case model.NodeCommentOff:
+		return model.ApiJson{State: false, Msg: "comment is disabled"}
+	case model.NodeCommentModerate:
+		comment.Pass = model.CommentPending
+	default:
+		comment.Pass = model.CommentApproved
+	}
+	comment.Content = SanitizeComment(comment.Content)
+	if strings.TrimSpace(comment.Content) == "" {
+		return model.ApiJson{State: false, Msg: "content is empty"}
+	}
+	result := model.CommentCreate(comment)
+	if result.State && comment.Pass == model.CommentApproved {
+		commentSync(comment.Articleid)
+	}
+	return result
+}
+
+/**
+ * 更新评论，只能修改内容，内容按白名单过滤
+ * @method CommentUpdate
+ * @param  {[type]} comment model.Comment [description]
+ */
+func CommentUpdate(comment model.Comment) model.ApiJson {
+	if comment.Id < 1 {
+		return model.ApiJson{State: false, Msg: "id is error"}
+	}
+	comment.Content = SanitizeComment(comment.Content)
+	if strings.TrimSpace(comment.Content) == "" {
+		return model.ApiJson{State: false, Msg: "content is empty"}
+	}
+	if len(model.CommentList([]int{comment.Id})) == 0 {
+		return model.ApiJson{State: false, Msg: "comment is no exist"}
+	}
+	if err := model.CommentContentSet(comment.Id, comment.Content); err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	return model.ApiJson{State: true}
+}
+
+/**
+ * 删除评论，同步文章的评论数
+ * @method CommentDele
+ * @param  {[type]} id int [description]
+ */
+func CommentDele(id int) model.ApiJson {
+	comments := model.CommentList([]int{id})
+	if len(comments) == 0 {
+		return model.ApiJson{State: false, Msg: "comment is no exist"}
+	}
+	result := model.CommentDel(id)
+	if result.State && comments[0].Pass == model.CommentApproved {
+		commentSync(comments[0].Articleid)
+	}
+	return result
+}
+
+/**
+ * 审核评论，审核通过的评论才会在前台显示并计入文章的评论数
+ * @method CommentPass
+ * @param  {[type]} ids  string [description]
+ * @param  {[type]} pass int    model.CommentApproved或model.CommentRejected
+ */
+func CommentPass(ids string, pass int) model.ApiJson {
+	idsInt := Tools.ParseIds(ids)
+	if len(idsInt) == 0 {
+		return model.ApiJson{State: false, Msg: "id is error"}
+	}
+	if pass != model.CommentApproved && pass != model.CommentRejected {
+		return model.ApiJson{State: false, Msg: "pass is error"}
+	}
+	comments := model.CommentList(idsInt)
+	if err := model.CommentPassSet(idsInt, pass); err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	synced := map[int]bool{}
+	for _, comment := range comments {
+		if comment.Pass != pass && !synced[comment.Articleid] {
+			synced[comment.Articleid] = true
+			commentSync(comment.Articleid)
+		}
+	}
+	return model.ApiJson{State: true}
+}
+
+//////////私有方法
+//按审核通过的评论更新文章的评论数和评论排行
+func commentSync(articleid int) {
+	if err := model.ArticleCommentSync([]int{articleid}); err != nil {
+		Tools.Logs("comment count " + Tools.ParseString(articleid) + ": " + err.Error())
+		return
+	}
+	RankSync([]int{articleid})
+	CacheInvalidateArticles([]int{articleid})
+}
+
+//评论人只能来自登录的会话，未登录时uid为0，username只作为显示的昵称
+func commentAuthor(comment model.Comment, user model.UserAdmin) model.Comment {
+	comment.Uid = user.ID
+	comment.Username = strings.TrimSpace(comment.Username)
+	if user.ID > 0 && comment.Username == "" {
+		comment.Username = user.Nickname
+	}
+	return comment
+}
@@ -0,0 +1,35 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+	"testing"
+)
+
+//没有id时必须在访问数据库之前拒绝
+func TestCommentUpdateRequiresID(t *testing.T) {
+	for _, id := range []int{0, -1} {
+		if result := CommentUpdate(model.Comment{Id: id, Content: "content"}); result.State {
+			t.Errorf("CommentUpdate() with id %d succeeded", id)
+		}
+	}
+}
+
+func TestCommentAuthor(t *testing.T) {
+	cases := []struct {
+		comment  model.Comment
+		user     model.UserAdmin
+		uid      int
+		username string
+	}{
+		{model.Comment{Uid: 1, Username: "admin"}, model.UserAdmin{}, 0, "admin"},
+		{model.Comment{Uid: 5, Username: " reader "}, model.UserAdmin{}, 0, "reader"},
+		{model.Comment{Uid: 1}, model.UserAdmin{ID: 3, Nickname: "editor"}, 3, "editor"},
+		{model.Comment{Username: "pen name"}, model.UserAdmin{ID: 3, Nickname: "editor"}, 3, "pen name"},
+	}
+	for _, c := range cases {
+		got := commentAuthor(c.comment, c.user)
+		if got.Uid != c.uid || got.Username != c.username {
+			t.Errorf("commentAuthor(%+v, %d) = %d %q, want %d %q", c.comment, c.user.ID, got.Uid, got.Username, c.uid, c.username)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package logic
+
+import (
+	"fmt"
+	"pizzaCmsApi/model"
+	"time"
+)
+
+//定时任务，按注册顺序执行
+var cronJobs = []cronJob{
+	{"article.schedule", ArticleSchedule},
+	{"article.views", ArticleViewFlush},
+	{"article.rank", RankSchedule},
+	{"article.purge", ArticleTrashPurge},
+}
+
+type cronJob struct {
+	Name string
+	Run  func()
+}
+
+/**
+ * 启动定时任务，每个任务执行前先获取redis锁，多个实例同时运行时只有一个会执行
+ * @method CronStart
+ */
+func CronStart() {
+	interval := time.Duration(Config.Cron.Interval) * time.Second
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		for {
+			for _, job := range cronJobs {
+				cronRun(job, Config.Cron.Interval)
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
+
+/**
+ * 定时发布和自动下线文章
+ * @method ArticleSchedule
+ */
+func ArticleSchedule() {
+	now := time.Now().Unix()
+	system := model.UserAdmin{Username: "system"}
+	if ids, err := model.ArticlePublishDue(now); err != nil {
+		Tools.Logs("article publish error: " + err.Error())
+	} else if len(ids) > 0 {
+		SearchSync(ids)
+		CacheInvalidateArticles(ids)
+		AuditLog(system, "", "article.publish", ids, nil, nil)
+	}
+	if ids, err := model.ArticleExpireDue(now); err != nil {
+		Tools.Logs("article expire error: " + err.Error())
+	} else if len(ids) > 0 {
+		SearchSync(ids)
+		CacheInvalidateArticles(ids)
+		AuditLog(system, "", "article.expire", ids, nil, nil)
+	}
+}
+
+//////////私有方法
+/**
+ * 加锁执行一个任务，任务panic不影响其他任务
+ * @method cronRun
+ */
+func cronRun(job cronJob, ex int) {
+	key := "lock:cron:" + job.Name
+	token := Tools.RandomHex(8)
+	if !Redis.Lock(key, token, ex) {
+		return
+	}
+	defer Redis.Unlock(key, token)
+	defer func() {
+		if err := recover(); err != nil {
+			Tools.Logs(fmt.Sprintf("cron %s panic: %v", job.Name, err))
+		}
+	}()
+	job.Run()
+}
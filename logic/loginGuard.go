@@ -0,0 +1,114 @@
+package logic
+
+import (
+	redigo "github.com/garyburd/redigo/redis"
+	"pizzaCmsApi/model"
+	"time"
+)
+
+const (
+	loginFailUserPrefix = "login:fail:user:" //用户名失败次数
+	loginFailIpPrefix   = "login:fail:ip:"   //ip失败次数
+	loginLockUserPrefix = "login:lock:user:" //用户名锁定
+	loginLockIpPrefix   = "login:lock:ip:"   //ip锁定
+)
+
+/**
+ * 判断用户名或者ip是否被锁定，返回剩余的锁定秒数
+ * @method LoginLocked
+ * @param  {[type]}    username string [description]
+ * @param  {[type]}    ip       string [description]
+ */
+func LoginLocked(username string, ip string) (bool, int) {
+	for _, key := range []string{loginLockUserPrefix + username, loginLockIpPrefix + ip} {
+		ttl, err := redigo.Int(Redis.Do("TTL", key))
+		if err == nil && ttl > 0 {
+			return true, ttl
+		}
+	}
+	return false, 0
+}
+
+/**
+ * 登录前的递增延迟，失败次数越多等待越久
+ * @method LoginDelay
+ * @param  {[type]}   username string [description]
+ */
+func LoginDelay(username string) {
+	fails, _ := redigo.Int(Redis.Do("GET", loginFailUserPrefix+username))
+	if delay := Tools.BackoffDelay(fails, Config.Login.Delay, Config.Login.MaxDelay); delay > 0 {
+		time.Sleep(time.Duration(delay) * time.Millisecond)
+	}
+}
+
+/**
+ * 记录一次登录失败，超过次数则锁定，返回用户名剩余可尝试次数
+ * @method LoginFail
+ * @param  {[type]}  username string [description]
+ * @param  {[type]}  ip       string [description]
+ */
+func LoginFail(username string, ip string) int {
+	userFails := loginIncr(loginFailUserPrefix + username)
+	ipFails := loginIncr(loginFailIpPrefix + ip)
+	if userFails >= Config.Login.MaxFail {
+		Redis.SetString(loginLockUserPrefix+username, Tools.ParseString(userFails), Tools.ParseString(Config.Login.Lockout))
+		Tools.Logs("useradmin " + username + " is locked, ip: " + ip)
+	}
+	if ipFails >= Config.Login.MaxIpFail {
+		Redis.SetString(loginLockIpPrefix+ip, Tools.ParseString(ipFails), Tools.ParseString(Config.Login.Lockout))
+		Tools.Logs("ip " + ip + " is locked")
+	}
+	if remain := Config.Login.MaxFail - userFails; remain > 0 {
+		return remain
+	}
+	return 0
+}
+
+/**
+ * 登录成功，清空用户名的失败次数
+ * @method LoginSuccess
+ * @param  {[type]}     username string [description]
+ */
+func LoginSuccess(username string) {
+	Redis.Del(loginFailUserPrefix + username)
+}
+
+/**
+ * 解除锁定，username和ip可以只传一个
+ * @method LoginUnlock
+ * @param  {[type]}    username string [description]
+ * @param  {[type]}    ip       string [description]
+ */
+func LoginUnlock(username string, ip string) model.ApiJson {
+	var keys []interface{}
+	if username != "" {
+		keys = append(keys, loginLockUserPrefix+username, loginFailUserPrefix+username)
+	}
+	if ip != "" {
+		keys = append(keys, loginLockIpPrefix+ip, loginFailIpPrefix+ip)
+	}
+	if len(keys) == 0 {
+		return model.ApiJson{State: false, Msg: "username or ip is required"}
+	}
+	if _, err := Redis.Del(keys...); err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	return model.ApiJson{State: true}
+}
+
+//////////私有方法
+/**
+ * 计数加1，第一次计数时设置过期时间
+ * @method loginIncr
+ */
+func loginIncr(key string) int {
+	n, err := redigo.Int(Redis.Do("INCR", key))
+	if err != nil {
+		Tools.Logs(err.Error())
+		return 0
+	}
+	if n == 1 {
+		Redis.Do("EXPIRE", key, Config.Login.Window)
+	}
+	return n
+}
@@ -0,0 +1,66 @@
+package logic
+
+import (
+	"github.com/russross/blackfriday"
+	"pizzaCmsApi/model"
+	"unicode/utf8"
+)
+
+const (
+	articleContentMax = 10000 //content字段的最大字数
+	markdownMax       = 20000 //markdown原文的最大字数
+	//支持github风格的表格、代码块、删除线和自动链接
+	markdownExtensions = blackfriday.EXTENSION_NO_INTRA_EMPHASIS |
+		blackfriday.EXTENSION_TABLES |
+		blackfriday.EXTENSION_FENCED_CODE |
+		blackfriday.EXTENSION_AUTOLINK |
+		blackfriday.EXTENSION_STRIKETHROUGH |
+		blackfriday.EXTENSION_SPACE_HEADERS |
+		blackfriday.EXTENSION_BACKSLASH_LINE_BREAK
+	markdownHtmlFlags = blackfriday.HTML_USE_XHTML
+)
+
+/**
+ * 把markdown渲染成html并过滤，结果和保存后的content一致
+ * @method MarkdownRender
+ * @param  {[type]} source string [description]
+ */
+func MarkdownRender(source string) string {
+	renderer := blackfriday.HtmlRenderer(markdownHtmlFlags, "", "")
+	return SanitizeArticle(string(blackfriday.Markdown([]byte(source), renderer, markdownExtensions)))
+}
+
+/**
+ * 预览markdown，不保存
+ * @method MarkdownPreview
+ * @param  {[type]} source string [description]
+ */
+func MarkdownPreview(source string) model.ApiJson {
+	content := MarkdownRender(source)
+	if utf8.RuneCountInString(content) > articleContentMax {
+		return model.ApiJson{State: false, Msg: "content is too long"}
+	}
+	return model.ApiJson{State: true, Msg: content}
+}
+
+//////////私有方法
+//按格式生成文章的content，markdown格式从原文渲染，html格式直接过滤
+func articleContent(article model.Article) (model.Article, string) {
+	switch article.Format {
+	case model.ArticleMarkdown:
+		if utf8.RuneCountInString(article.Markdown) > markdownMax {
+			return article, "markdown is too long"
+		}
+		article.Content = MarkdownRender(article.Markdown)
+	case "", model.ArticleHtml:
+		article.Format = model.ArticleHtml
+		article.Markdown = ""
+		article.Content = SanitizeArticle(article.Content)
+	default:
+		return article, "format is error"
+	}
+	if utf8.RuneCountInString(article.Content) > articleContentMax {
+		return article, "content is too long"
+	}
+	return article, ""
+}
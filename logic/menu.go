@@ -0,0 +1,309 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+	"strings"
+)
+
+type MenuTreeItem struct {
+	model.MenuItem
+	Slug     string          `json:"slug"` //文章的固定链接
+	Children []*MenuTreeItem `json:"children"`
+}
+
+/**
+ * 前台获取菜单，节点和文章的名称、链接按当前数据填充，隐藏的节点和不可见的文章连同下级菜单项一起去掉，带缓存
+ * @method MenuGet
+ * @param  {[type]} name string 菜单调用标识
+ */
+func MenuGet(name string) model.ApiJson {
+	menu := model.MenuFindByName(name)
+	if menu.ID == 0 {
+		return model.ApiJson{State: false, Msg: "menu is no exist"}
+	}
+	var tree []*MenuTreeItem
+	//节点和文章变化都会影响菜单的内容
+	tags := []string{CacheTagMenu, CacheTagNode, CacheTagList}
+	err := Redis.Remember("menu", "menu:"+Tools.ParseString(menu.ID), cacheTTL(Config.Cache.List), tags, &tree, func() (interface{}, error) {
+		return menuTree(menuResolve(model.MenuItems(menu.ID))), nil
+	})
+	if err != nil {
+		tree = menuTree(menuResolve(model.MenuItems(menu.ID)))
+	}
+	return model.ApiJson{State: true, Msg: tree}
+}
+
+/**
+ * 后台获取菜单的所有菜单项，不过滤、不填充
+ * @method MenuItemTree
+ * @param  {[type]} menuid int [description]
+ */
+func MenuItemTree(menuid int) model.ApiJson {
+	if model.MenuFind(menuid).ID == 0 {
+		return model.ApiJson{State: false, Msg: "menu is no exist"}
+	}
+	var items []MenuTreeItem
+	for _, item := range model.MenuItems(menuid) {
+		items = append(items, MenuTreeItem{MenuItem: item})
+	}
+	return model.ApiJson{State: true, Msg: menuTree(items)}
+}
+
+/**
+ * 创建菜单
+ * @method MenuCreate
+ * @param  {[type]} menu model.Menu [description]
+ */
+func MenuCreate(menu model.Menu) model.ApiJson {
+	menu.Name = strings.TrimSpace(menu.Name)
+	return model.MenuCreate(menu)
+}
+
+/**
+ * 更新菜单
+ * @method MenuUpdate
+ * @param  {[type]} menu model.Menu [description]
+ */
+func MenuUpdate(menu model.Menu) model.ApiJson {
+	if model.MenuFind(menu.ID).ID == 0 {
+		return model.ApiJson{State: false, Msg: "menu is no exist"}
+	}
+	menu.Name = strings.TrimSpace(menu.Name)
+	result := model.MenuUpdate(menu)
+	if result.State {
+		Redis.Invalidate(CacheTagMenu)
+	}
+	return result
+}
+
+/**
+ * 删除菜单和它的所有菜单项
+ * @method MenuDele
+ * @param  {[type]} id int [description]
+ */
+func MenuDele(id int) model.ApiJson {
+	if model.MenuFind(id).ID == 0 {
+		return model.ApiJson{State: false, Msg: "menu is no exist"}
+	}
+	if err := model.MenuDele(id); err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	Redis.Invalidate(CacheTagMenu)
+	return model.ApiJson{State: true}
+}
+
+/**
+ * 创建菜单项
+ * @method MenuItemCreate
+ * @param  {[type]} item model.MenuItem [description]
+ */
+func MenuItemCreate(item model.MenuItem) model.ApiJson {
+	if model.MenuFind(item.Menuid).ID == 0 {
+		return model.ApiJson{State: false, Msg: "menu is no exist"}
+	}
+	if item.Pid > 0 && model.MenuItemFind(item.Pid).Menuid != item.Menuid {
+		return model.ApiJson{State: false, Msg: "parent menu item is no exist"}
+	}
+	item, msg := menuItemCheck(item)
+	if msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	result := model.MenuItemCreate(item)
+	if result.State {
+		Redis.Invalidate(CacheTagMenu)
+	}
+	return result
+}
+
+/**
+ * 更新菜单项的类型、链接和名称
+ * @method MenuItemUpdate
+ * @param  {[type]} item model.MenuItem [description]
+ */
+func MenuItemUpdate(item model.MenuItem) model.ApiJson {
+	if model.MenuItemFind(item.ID).ID == 0 {
+		return model.ApiJson{State: false, Msg: "menu item is no exist"}
+	}
+	item, msg := menuItemCheck(item)
+	if msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	result := model.MenuItemUpdate(item)
+	if result.State {
+		Redis.Invalidate(CacheTagMenu)
+	}
+	return result
+}
+
+/**
+ * 删除菜单项，下级菜单项一起删除
+ * @method MenuItemDele
+ * @param  {[type]} id int [description]
+ */
+func MenuItemDele(id int) model.ApiJson {
+	ids, err := model.MenuItemDele(id)
+	if err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	Redis.Invalidate(CacheTagMenu)
+	return model.ApiJson{State: true, Msg: ids, Count: len(ids)}
+}
+
+/**
+ * 保存拖动排序的结果，可以同时修改上级菜单项，不能形成循环
+ * @method MenuItemSort
+ * @param  {[type]} menuid int                   [description]
+ * @param  {[type]} orders []model.MenuItemOrder 需要修改位置的菜单项
+ */
+func MenuItemSort(menuid int, orders []model.MenuItemOrder) model.ApiJson {
+	if model.MenuFind(menuid).ID == 0 {
+		return model.ApiJson{State: false, Msg: "menu is no exist"}
+	}
+	parents := map[int]int{}
+	for _, item := range model.MenuItems(menuid) {
+		parents[item.ID] = item.Pid
+	}
+	for _, order := range orders {
+		if _, ok := parents[order.ID]; !ok {
+			return model.ApiJson{State: false, Msg: "menu item " + Tools.ParseString(order.ID) + " is not in this menu"}
+		}
+		if _, ok := parents[order.Pid]; order.Pid != 0 && !ok {
+			return model.ApiJson{State: false, Msg: "parent menu item " + Tools.ParseString(order.Pid) + " is not in this menu"}
+		}
+		parents[order.ID] = order.Pid
+	}
+	//按修改后的上级关系检查
+	if menuHasLoop(parents) {
+		return model.ApiJson{State: false, Msg: "menu items can not be nested in a loop"}
+	}
+	if err := model.MenuItemSort(menuid, orders); err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	Redis.Invalidate(CacheTagMenu)
+	return model.ApiJson{State: true}
+}
+
+//////////私有方法
+//检查上级关系是否有环，parents为id => 上级id，上级id不在parents中时当作顶级处理
+//没有环时每个菜单项向上最多经过len(parents)步到达顶级
+func menuHasLoop(parents map[int]int) bool {
+	for id := range parents {
+		pid, steps := parents[id], 0
+		for pid != 0 && steps <= len(parents) {
+			pid = parents[pid]
+			steps++
+		}
+		if pid != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+//检查菜单项的链接目标，节点和文章必须存在，外部链接只允许http、https和站内路径
+func menuItemCheck(item model.MenuItem) (model.MenuItem, string) {
+	item.Title = strings.TrimSpace(item.Title)
+	item.Url = strings.TrimSpace(item.Url)
+	switch item.Kind {
+	case model.MenuNode:
+		if model.NodeFind(item.Targetid).ID == 0 {
+			return item, "node is no exist"
+		}
+		item.Url = ""
+	case model.MenuArticle:
+		if len(model.ArticleList([]int{item.Targetid})) == 0 {
+			return item, "article is no exist"
+		}
+		item.Url = ""
+	case model.MenuLink:
+		lower := strings.ToLower(item.Url)
+		if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") && !(strings.HasPrefix(item.Url, "/") && !strings.HasPrefix(item.Url, "//")) {
+			return item, "url must start with http://, https:// or /"
+		}
+		if item.Title == "" {
+			return item, "title is required"
+		}
+		item.Targetid = 0
+	default:
+		return item, "kind must be node, article or link"
+	}
+	return item, ""
+}
+
+//填充节点和文章的名称、链接，去掉前台看不到的目标
+func menuResolve(items []model.MenuItem) []MenuTreeItem {
+	var nodeids, articleids []int
+	for _, item := range items {
+		switch item.Kind {
+		case model.MenuNode:
+			nodeids = append(nodeids, item.Targetid)
+		case model.MenuArticle:
+			articleids = append(articleids, item.Targetid)
+		}
+	}
+	nodes := map[int]model.Node{}
+	if len(nodeids) > 0 {
+		for _, node := range model.NodeList(nodeids) {
+			if node.Hidden == 0 {
+				nodes[node.ID] = node
+			}
+		}
+	}
+	articles := map[int]model.ArticleResults{}
+	if len(articleids) > 0 {
+		allowed := model.SearchFilter(articleids, 0, -1, 0, 0, true)
+		var visible []int
+		for id := range allowed {
+			visible = append(visible, id)
+		}
+		for _, article := range model.ArticlesPublic(model.ArticleResultsList(visible)) {
+			articles[article.ID] = article
+		}
+	}
+	resolved := []MenuTreeItem{}
+	for _, item := range items {
+		tree := MenuTreeItem{MenuItem: item}
+		switch item.Kind {
+		case model.MenuNode:
+			node, ok := nodes[item.Targetid]
+			if !ok {
+				continue
+			}
+			if tree.Title == "" {
+				tree.Title = node.Name
+			}
+			tree.Url = node.Link
+		case model.MenuArticle:
+			article, ok := articles[item.Targetid]
+			if !ok {
+				continue
+			}
+			if tree.Title == "" {
+				tree.Title = article.Title
+			}
+			tree.Url = article.Link
+			tree.Slug = article.Slug
+		}
+		resolved = append(resolved, tree)
+	}
+	return resolved
+}
+
+//按pid组装成树，上级菜单项不存在的去掉
+func menuTree(items []MenuTreeItem) []*MenuTreeItem {
+	byId := make(map[int]*MenuTreeItem, len(items))
+	for i := range items {
+		items[i].Children = []*MenuTreeItem{}
+		byId[items[i].ID] = &items[i]
+	}
+	tree := []*MenuTreeItem{}
+	for i := range items {
+		item := &items[i]
+		if item.Pid == 0 {
+			tree = append(tree, item)
+		} else if parent, ok := byId[item.Pid]; ok {
+			parent.Children = append(parent.Children, item)
+		}
+	}
+	return tree
+}
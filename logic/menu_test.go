@@ -0,0 +1,16 @@
+package logic
+
+import "testing"
+
+//拖动排序后的上级关系有环时必须拒绝，否则菜单树里的菜单项会消失
+func TestMenuHasLoop(t *testing.T) {
+	if menuHasLoop(map[int]int{1: 0, 2: 1, 3: 2, 4: 9}) {
+		t.Error("menuHasLoop() found a loop in a chain")
+	}
+	for _, parents := range []map[int]int{{1: 1}, {1: 2, 2: 1}, {1: 0, 2: 4, 3: 2, 4: 3}} {
+		if !menuHasLoop(parents) {
+			t.Errorf("menuHasLoop(%v) missed the loop", parents)
+		}
+	}
+}
+
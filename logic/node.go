@@ -0,0 +1,193 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+)
+
+type NodeTreeItem struct {
+	model.Node
+	Children []*NodeTreeItem `json:"children"`
+}
+
+type NodeCrumb struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Link string `json:"link"`
+}
+
+/**
+ * 节点树，同级节点按权重降序，带缓存
+ * @method NodeTree
+ * @param  {[type]} public bool 前台读取，不包含隐藏的节点
+ */
+func NodeTree(public bool) model.ApiJson {
+	var tree []*NodeTreeItem
+	key := "node:tree"
+	if public {
+		key += ":public"
+	}
+	err := Redis.Remember("node.tree", key, cacheTTL(Config.Cache.List), []string{CacheTagNode}, &tree, func() (interface{}, error) {
+		return nodeTree(nodeVisible(model.NodeAll(), public)), nil
+	})
+	if err != nil {
+		tree = nodeTree(nodeVisible(model.NodeAll(), public))
+	}
+	return model.ApiJson{State: true, Msg: tree}
+}
+
+/**
+ * 面包屑导航，从顶级节点到当前节点，隐藏的节点返回不存在，带缓存
+ * @method NodeBreadcrumb
+ * @param  {[type]} id int [description]
+ */
+func NodeBreadcrumb(id int) model.ApiJson {
+	var crumbs []NodeCrumb
+	err := Redis.Remember("node.breadcrumb", "node:breadcrumb:"+Tools.ParseString(id), cacheTTL(Config.Cache.List), []string{CacheTagNode}, &crumbs, func() (interface{}, error) {
+		return nodeBreadcrumb(id), nil
+	})
+	if err != nil {
+		crumbs = nodeBreadcrumb(id)
+	}
+	if len(crumbs) == 0 {
+		return model.ApiJson{State: false, Msg: "node is no exist"}
+	}
+	return model.ApiJson{State: true, Msg: crumbs, Count: len(crumbs)}
+}
+
+/**
+ * 创建节点
+ * @method NodeCreate
+ * @param  {[type]} node model.Node [description]
+ */
+func NodeCreate(node model.Node) model.ApiJson {
+	if msg := nodeSettingCheck(node); msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	result := model.NodeCreate(node)
+	if result.State {
+		Redis.Invalidate(CacheTagNode)
+	}
+	return result
+}
+
+/**
+ * 更新节点名称、简介、链接、权重和节点设置，可见性变化时清除子树中文章的缓存
+ * @method NodeUpdate
+ * @param  {[type]} node model.Node [description]
+ */
+func NodeUpdate(node model.Node) model.ApiJson {
+	old := model.NodeFind(node.ID)
+	if old.ID == 0 {
+		return model.ApiJson{State: false, Msg: "node is no exist"}
+	}
+	if msg := nodeSettingCheck(node); msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	result := model.NodeUpdate(node)
+	if result.State {
+		if old.Visibility != node.Visibility {
+			CacheInvalidateArticles(model.NodeArticleIds(node.ID))
+		}
+		Redis.Invalidate(CacheTagNode)
+	}
+	return result
+}
+
+/**
+ * 删除节点，有下级节点或文章时需要指定转移到的节点
+ * @method NodeDele
+ * @param  {[type]} id int [description]
+ * @param  {[type]} to int 转移到的节点id，0表示不转移
+ */
+func NodeDele(id int, to int) model.ApiJson {
+	articleids, err := model.NodeDele(id, to)
+	if err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	nodeChanged(articleids)
+	return model.ApiJson{State: true, Count: len(articleids)}
+}
+
+/**
+ * 移动节点，子树中文章的排行榜同步更新
+ * @method NodeMove
+ * @param  {[type]} id  int [description]
+ * @param  {[type]} pid int 新的上级节点id，0表示顶级
+ */
+func NodeMove(id int, pid int) model.ApiJson {
+	articleids, err := model.NodeMove(id, pid)
+	if err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	nodeChanged(articleids)
+	return model.ApiJson{State: true, Count: len(articleids)}
+}
+
+/**
+ * 合并节点，原节点的下级节点和文章转移到目标节点后删除原节点
+ * @method NodeMerge
+ * @param  {[type]} from int [description]
+ * @param  {[type]} to   int [description]
+ */
+func NodeMerge(from int, to int) model.ApiJson {
+	articleids, err := model.NodeMerge(from, to)
+	if err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	nodeChanged(articleids)
+	return model.ApiJson{State: true, Count: len(articleids)}
+}
+
+//////////私有方法
+//节点结构变化后更新文章所在的排行榜，清除节点和列表缓存
+func nodeChanged(articleids []int) {
+	if len(articleids) > 0 {
+		RankSync(articleids)
+		CacheInvalidateArticles(articleids)
+	}
+	Redis.Invalidate(CacheTagNode)
+}
+
+//按nodepath从顶级排到当前节点，节点不存在或隐藏时为空
+func nodeBreadcrumb(id int) []NodeCrumb {
+	chain := nodeChain(id)
+	crumbs := []NodeCrumb{}
+	if len(chain) == 0 || chain[0].Hidden == 1 {
+		return crumbs
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		crumbs = append(crumbs, NodeCrumb{ID: chain[i].ID, Name: chain[i].Name, Link: chain[i].Link})
+	}
+	return crumbs
+}
+
+//前台只保留没有隐藏的节点
+func nodeVisible(nodes []model.Node, public bool) []model.Node {
+	if !public {
+		return nodes
+	}
+	visible := []model.Node{}
+	for _, node := range nodes {
+		if node.Hidden == 0 {
+			visible = append(visible, node)
+		}
+	}
+	return visible
+}
+
+//按pid组装成树，上级节点不存在的作为顶级节点
+func nodeTree(nodes []model.Node) []*NodeTreeItem {
+	items := make(map[int]*NodeTreeItem, len(nodes))
+	for _, node := range nodes {
+		items[node.ID] = &NodeTreeItem{Node: node, Children: []*NodeTreeItem{}}
+	}
+	tree := []*NodeTreeItem{}
+	for _, node := range nodes {
+		if parent, ok := items[node.Pid]; ok && node.Pid != node.ID {
+			parent.Children = append(parent.Children, items[node.ID])
+		} else {
+			tree = append(tree, items[node.ID])
+		}
+	}
+	return tree
+}
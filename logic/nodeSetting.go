@@ -0,0 +1,137 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+	"strings"
+)
+
+const (
+	nodeDefaultComment  = model.NodeCommentOn //顶级节点也没有设置时的默认值
+	nodeDefaultSort     = "new"
+	nodeDefaultPagesize = 20
+)
+
+//继承上级节点后的节点设置
+type NodeSetting struct {
+	Nodeid      int    `json:"nodeid"`
+	Hidden      bool   `json:"hidden"`
+	Comment     string `json:"comment"`
+	Sort        string `json:"sort"`
+	Pagesize    int    `json:"pagesize"`
+	SeoTitle    string `json:"seo_title"`
+	SeoKeywords string `json:"seo_keywords"`
+}
+
+/**
+ * 获取节点继承后的设置，没有设置的项从最近的上级节点继承，带缓存
+ * nodeid为0或节点不存在时返回默认设置
+ * @method NodeSettingGet
+ * @param  {[type]} nodeid int [description]
+ */
+func NodeSettingGet(nodeid int) NodeSetting {
+	if nodeid <= 0 {
+		return nodeSetting(nil)
+	}
+	var setting NodeSetting
+	err := Redis.Remember("node.setting", "node:setting:"+Tools.ParseString(nodeid), cacheTTL(Config.Cache.List), []string{CacheTagNode}, &setting, func() (interface{}, error) {
+		return nodeSetting(nodeChain(nodeid)), nil
+	})
+	if err != nil {
+		return nodeSetting(nodeChain(nodeid))
+	}
+	return setting
+}
+
+/**
+ * 前台获取节点设置，隐藏的节点返回不存在
+ * @method NodeSettingPublic
+ * @param  {[type]} nodeid int [description]
+ */
+func NodeSettingPublic(nodeid int) model.ApiJson {
+	setting := NodeSettingGet(nodeid)
+	if setting.Nodeid == 0 || setting.Hidden {
+		return model.ApiJson{State: false, Msg: "node is no exist"}
+	}
+	return model.ApiJson{State: true, Msg: setting}
+}
+
+//////////私有方法
+//校验节点设置，为空表示继承
+func nodeSettingCheck(node model.Node) string {
+	switch node.Visibility {
+	case "", model.NodePublic, model.NodeHidden:
+	default:
+		return "visibility must be public or hidden"
+	}
+	switch node.Comment {
+	case "", model.NodeCommentOn, model.NodeCommentModerate, model.NodeCommentOff:
+	default:
+		return "comment must be enabled, moderated or disabled"
+	}
+	if _, ok := model.NodeSorts[node.Sort]; node.Sort != "" && !ok {
+		return "sort is error"
+	}
+	return ""
+}
+
+//节点和它的所有上级节点，从自身到顶级排列，节点不存在时为空
+func nodeChain(nodeid int) []model.Node {
+	node := model.NodeFind(nodeid)
+	if node.ID == 0 {
+		return nil
+	}
+	var ids []int
+	for _, id := range strings.Split(strings.Trim(node.Nodepath, ","), ",") {
+		if i := Tools.ParseInt(id, 0); i > 0 && i != node.ID {
+			ids = append(ids, i)
+		}
+	}
+	parents := map[int]model.Node{}
+	for _, parent := range model.NodeList(ids) {
+		parents[parent.ID] = parent
+	}
+	chain := []model.Node{node}
+	for i := len(ids) - 1; i >= 0; i-- {
+		if parent, ok := parents[ids[i]]; ok {
+			chain = append(chain, parent)
+		}
+	}
+	return chain
+}
+
+//按继承关系合并设置，seo标题不继承，为空时使用节点名称
+func nodeSetting(chain []model.Node) NodeSetting {
+	setting := NodeSetting{}
+	if len(chain) > 0 {
+		setting.Nodeid = chain[0].ID
+		setting.Hidden = chain[0].Hidden == 1
+		setting.SeoTitle = chain[0].SeoTitle
+		if setting.SeoTitle == "" {
+			setting.SeoTitle = chain[0].Name
+		}
+	}
+	for _, node := range chain {
+		if setting.Comment == "" {
+			setting.Comment = node.Comment
+		}
+		if setting.Sort == "" {
+			setting.Sort = node.Sort
+		}
+		if setting.Pagesize == 0 {
+			setting.Pagesize = node.Pagesize
+		}
+		if setting.SeoKeywords == "" {
+			setting.SeoKeywords = node.SeoKeywords
+		}
+	}
+	if setting.Comment == "" {
+		setting.Comment = nodeDefaultComment
+	}
+	if _, ok := model.NodeSorts[setting.Sort]; !ok {
+		setting.Sort = nodeDefaultSort
+	}
+	if setting.Pagesize <= 0 {
+		setting.Pagesize = nodeDefaultPagesize
+	}
+	return setting
+}
@@ -0,0 +1,326 @@
+package logic
+
+import (
+	redigo "github.com/garyburd/redigo/redis"
+	"math"
+	"pizzaCmsApi/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//排行榜类型，today和week按redis中每天的浏览数计算，每天的浏览数同时记在数据库中，都可以从数据库重建
+const (
+	RankToday   = "today"   //今日热门
+	RankWeek    = "week"    //本周热门，越早的浏览权重越低
+	RankAll     = "all"     //总浏览数
+	RankReco    = "reco"    //推荐，按推荐值和发布时间
+	RankComment = "comment" //评论最多
+)
+
+const (
+	rankPrefix   = "rank:"       //排行榜，rank:类型:节点id，节点id为0表示全部
+	rankDayKey   = "rank:day:"   //每天的浏览数，rank:day:20160312:节点id
+	rankKeysKey  = "rank:keys"   //可以重建的排行榜key
+	rankBuiltKey = "rank:built"  //存在时表示最近重建过
+	rankDayKeep  = 31            //每天浏览数的保留天数
+	rankMaxRound = 5             //过滤掉不可见文章后数量不够时最多再取几次
+	rankRecoBase = 10000000000.0 //推荐值的倍数，保证推荐值优先于发布时间
+)
+
+//可以从数据库重建的排行榜和分数计算方式
+var rankScores = map[string]func(model.ArticleRank) float64{
+	RankAll: func(a model.ArticleRank) float64 { return float64(a.Count) },
+	RankReco: func(a model.ArticleRank) float64 {
+		if a.Reco <= 0 {
+			return 0
+		}
+		return float64(a.Reco)*rankRecoBase + float64(a.Createtime)
+	},
+	RankComment: func(a model.ArticleRank) float64 { return float64(a.Comment) },
+}
+
+type RankItem struct {
+	model.ArticleResults
+	Score float64 `json:"score"`
+}
+
+/**
+ * 获取排行榜，只返回前台可见的文章
+ * @method Rank
+ * @param  {[type]} kind   string 排行榜类型
+ * @param  {[type]} nodeid int    节点id，包含子节点，0表示全部
+ * @param  {[type]} limit  int    [description]
+ */
+func Rank(kind string, nodeid int, limit int) model.ApiJson {
+	if !rankEnabled(kind) {
+		return model.ApiJson{State: false, Msg: "rank type is error"}
+	}
+	var key string
+	switch kind {
+	case RankToday:
+		key = rankDay(time.Now(), nodeid)
+	case RankWeek:
+		key = rankWeek(nodeid)
+	case RankAll, RankReco, RankComment:
+		key = rankPrefix + kind + ":" + Tools.ParseString(nodeid)
+	default:
+		return model.ApiJson{State: false, Msg: "rank type is error"}
+	}
+	items := []RankItem{}
+	for round, start := 0, 0; round < rankMaxRound && len(items) < limit; round++ {
+		values, err := redigo.Strings(Redis.Do("ZREVRANGE", key, start, start+limit*2-1, "WITHSCORES"))
+		if err != nil || len(values) == 0 {
+			break
+		}
+		start += len(values) / 2
+		var ids []int
+		scores := map[int]float64{}
+		for i := 0; i+1 < len(values); i += 2 {
+			id := Tools.ParseInt(values[i], 0)
+			ids = append(ids, id)
+			scores[id], _ = strconv.ParseFloat(values[i+1], 64)
+		}
+		//节点变化、下线的文章还留在排行榜中，取的时候过滤
+		allowed := model.SearchFilter(ids, nodeid, -1, 0, 0, true)
+		var visible []int
+		for _, id := range ids {
+			if allowed[id] {
+				visible = append(visible, id)
+			}
+		}
+		for _, article := range model.ArticlesPublic(model.ArticleResultsList(visible)) {
+			if len(items) >= limit {
+				break
+			}
+			items = append(items, RankItem{ArticleResults: article, Score: scores[article.ID]})
+		}
+	}
+	return model.ApiJson{State: true, Msg: items, Count: len(items)}
+}
+
+/**
+ * 把写入数据库的浏览数同步到今日和总排行，由浏览数定时任务调用
+ * @method RankViews
+ * @param  {[type]} deltas map[int]int 文章id=>增加的浏览数
+ */
+func RankViews(deltas map[int]int) {
+	if len(deltas) == 0 {
+		return
+	}
+	ids := make([]int, 0, len(deltas))
+	for id := range deltas {
+		ids = append(ids, id)
+	}
+	now := time.Now()
+	expire := map[string]bool{}
+	for id, path := range model.ArticleNodePaths(ids) {
+		member := Tools.ParseString(id)
+		for _, nodeid := range rankNodes(path) {
+			if rankEnabled(RankToday) || rankEnabled(RankWeek) {
+				day := rankDay(now, nodeid)
+				Redis.Do("ZINCRBY", day, deltas[id], member)
+				expire[day] = true
+			}
+			if rankEnabled(RankAll) {
+				Redis.Do("ZINCRBY", rankPrefix+RankAll+":"+Tools.ParseString(nodeid), deltas[id], member)
+			}
+		}
+	}
+	for key := range expire {
+		Redis.Do("EXPIRE", key, rankDayKeep*86400)
+	}
+}
+
+/**
+ * 文章修改后更新推荐、评论和总排行
+ * @method RankSync
+ * @param  {[type]} ids []int [description]
+ */
+func RankSync(ids []int) {
+	for _, article := range model.ArticleRankList(ids) {
+		member := Tools.ParseString(article.ID)
+		for kind, score := range rankScores {
+			if !rankEnabled(kind) {
+				continue
+			}
+			s := score(article)
+			for _, nodeid := range rankNodes(article.Nodepath) {
+				key := rankPrefix + kind + ":" + Tools.ParseString(nodeid)
+				if s > 0 {
+					Redis.Do("ZADD", key, s, member)
+					Redis.Do("SADD", rankKeysKey, key)
+				} else {
+					Redis.Do("ZREM", key, member)
+				}
+			}
+		}
+	}
+}
+
+/**
+ * 从数据库重建推荐、评论和总排行，以及redis中丢失的每天浏览数，redis数据丢失后也可以恢复
+ * @method RankRebuild
+ */
+func RankRebuild() error {
+	sets := map[string][]interface{}{}
+	for _, article := range model.ArticleRankAll() {
+		member := Tools.ParseString(article.ID)
+		for kind, score := range rankScores {
+			if !rankEnabled(kind) {
+				continue
+			}
+			s := score(article)
+			if s <= 0 {
+				continue
+			}
+			for _, nodeid := range rankNodes(article.Nodepath) {
+				key := rankPrefix + kind + ":" + Tools.ParseString(nodeid)
+				sets[key] = append(sets[key], s, member)
+			}
+		}
+	}
+	old, _ := Redis.SMembers(rankKeysKey)
+	for key, members := range sets {
+		if err := rankStore(key, members); err != nil {
+			return err
+		}
+		Redis.Do("SADD", rankKeysKey, key)
+	}
+	//已经没有文章或者关闭了的排行榜
+	for _, key := range old {
+		if _, ok := sets[key]; !ok {
+			Redis.Del(key)
+			Redis.Do("SREM", rankKeysKey, key)
+		}
+	}
+	return rankRebuildDays()
+}
+
+/**
+ * 定时重建排行榜，间隔由配置决定，redis被清空后会立即重建
+ * @method RankSchedule
+ */
+func RankSchedule() {
+	if exists, _ := redigo.Bool(Redis.Do("EXISTS", rankBuiltKey)); exists {
+		return
+	}
+	if err := RankRebuild(); err != nil {
+		Tools.Logs("rank rebuild error: " + err.Error())
+		return
+	}
+	interval := Config.Rank.Rebuild
+	if interval <= 0 {
+		interval = 600
+	}
+	Redis.Do("SET", rankBuiltKey, time.Now().Unix(), "EX", interval)
+}
+
+//////////私有方法
+func rankDay(t time.Time, nodeid int) string {
+	return rankDayKey + t.Format("20060102") + ":" + Tools.ParseString(nodeid)
+}
+
+//合并最近几天的浏览数，越早的权重越低，结果短时间缓存
+func rankWeek(nodeid int) string {
+	key := rankPrefix + RankWeek + ":" + Tools.ParseString(nodeid)
+	if exists, _ := redigo.Bool(Redis.Do("EXISTS", key)); exists {
+		return key
+	}
+	days := rankDays()
+	args := []interface{}{key, days}
+	weights := []interface{}{"WEIGHTS"}
+	now := time.Now()
+	for i := 0; i < days; i++ {
+		args = append(args, rankDay(now.AddDate(0, 0, -i), nodeid))
+		weights = append(weights, math.Pow(Config.Rank.Decay, float64(i)))
+	}
+	cache := Config.Rank.Cache
+	if cache <= 0 {
+		cache = 60
+	}
+	if _, err := Redis.Do("ZUNIONSTORE", append(args, weights...)...); err == nil {
+		Redis.Do("EXPIRE", key, cache)
+	}
+	return key
+}
+
+//本周热门统计的天数
+func rankDays() int {
+	if Config.Rank.Days <= 0 {
+		return 7
+	}
+	return Config.Rank.Days
+}
+
+//排行榜类型是否开启，没有配置时全部开启
+func rankEnabled(kind string) bool {
+	if len(Config.Rank.Kinds) == 0 {
+		return kind == RankToday || kind == RankWeek || kind == RankAll || kind == RankReco || kind == RankComment
+	}
+	for _, k := range Config.Rank.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+//先写入临时key再改名，重建过程中不影响读取
+func rankStore(key string, members []interface{}) error {
+	tmp := key + ":tmp"
+	Redis.Del(tmp)
+	for i := 0; i < len(members); i += 1000 {
+		end := i + 1000
+		if end > len(members) {
+			end = len(members)
+		}
+		if _, err := Redis.Do("ZADD", append([]interface{}{tmp}, members[i:end]...)...); err != nil {
+			return err
+		}
+	}
+	_, err := Redis.Do("RENAME", tmp, key)
+	return err
+}
+
+//从数据库重建redis中丢失的每天浏览数，并删除超过保留天数的记录
+func rankRebuildDays() error {
+	now := time.Now()
+	if err := model.ArticleViewDayPurge(Tools.ParseInt(now.AddDate(0, 0, -rankDayKeep).Format("20060102"), 0)); err != nil {
+		return err
+	}
+	if !rankEnabled(RankToday) && !rankEnabled(RankWeek) {
+		return nil
+	}
+	for i := 0; i < rankDays() && i < rankDayKeep; i++ {
+		t := now.AddDate(0, 0, -i)
+		if exists, _ := redigo.Bool(Redis.Do("EXISTS", rankDay(t, 0))); exists {
+			continue
+		}
+		sets := map[string][]interface{}{}
+		for _, article := range model.ArticleViewDayList(Tools.ParseInt(t.Format("20060102"), 0)) {
+			for _, nodeid := range rankNodes(article.Nodepath) {
+				key := rankDay(t, nodeid)
+				sets[key] = append(sets[key], article.Count, Tools.ParseString(article.ID))
+			}
+		}
+		for key, members := range sets {
+			if err := rankStore(key, members); err != nil {
+				return err
+			}
+			Redis.Do("EXPIRE", key, (rankDayKeep-i)*86400)
+		}
+	}
+	return nil
+}
+
+//文章计入的排行榜节点：全部(0)和节点路径上的每个节点
+func rankNodes(nodepath string) []int {
+	nodes := []int{0}
+	for _, id := range strings.Split(strings.Trim(nodepath, ","), ",") {
+		if nodeid := Tools.ParseInt(id, 0); nodeid > 0 {
+			nodes = append(nodes, nodeid)
+		}
+	}
+	return nodes
+}
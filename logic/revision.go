@@ -0,0 +1,125 @@
+package logic
+
+import (
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"pizzaCmsApi/model"
+	"time"
+)
+
+/**
+ * 文章的版本列表，只能查看授权节点下的文章
+ * @method RevisionPage
+ * @param  {[type]} articleid int             [description]
+ * @param  {[type]} cp        int             [description]
+ * @param  {[type]} mp        int             [description]
+ * @param  {[type]} user      model.UserAdmin 当前管理员
+ */
+func RevisionPage(articleid int, cp int, mp int, user model.UserAdmin) model.ApiJson {
+	if !ArticleAllowed(user.ID, []int{articleid}) {
+		return model.ApiJson{State: false, Msg: "permission denied"}
+	}
+	return model.RevisionPage(articleid, cp, mp)
+}
+
+/**
+ * 获取某个版本，只能查看授权节点下的文章
+ * @method RevisionGet
+ * @param  {[type]} id   int             [description]
+ * @param  {[type]} user model.UserAdmin 当前管理员
+ */
+func RevisionGet(id int, user model.UserAdmin) model.ApiJson {
+	revision := model.RevisionGet(id)
+	if revision.ID == 0 {
+		return model.ApiJson{State: false, Msg: "revision is no exist"}
+	}
+	if !ArticleAllowed(user.ID, []int{revision.Articleid}) {
+		return model.ApiJson{State: false, Msg: "permission denied"}
+	}
+	return model.ApiJson{State: true, Msg: revision}
+}
+
+/**
+ * 对比两个版本，content返回逐段的差异，其他字段返回有变化的字段名
+ * @method RevisionDiff
+ * @param  {[type]} from int             旧版本id
+ * @param  {[type]} to   int             新版本id
+ * @param  {[type]} user model.UserAdmin 当前管理员
+ */
+func RevisionDiff(from int, to int, user model.UserAdmin) model.ApiJson {
+	a := model.RevisionGet(from)
+	b := model.RevisionGet(to)
+	if a.ID == 0 || b.ID == 0 {
+		return model.ApiJson{State: false, Msg: "revision is no exist"}
+	}
+	if a.Articleid != b.Articleid {
+		return model.ApiJson{State: false, Msg: "revisions are not the same article"}
+	}
+	if !ArticleAllowed(user.ID, []int{a.Articleid}) {
+		return model.ApiJson{State: false, Msg: "permission denied"}
+	}
+	dmp := diffmatchpatch.New()
+	//两个版本都是markdown时对比原文
+	source, target := a.Content, b.Content
+	if a.Format == model.ArticleMarkdown && b.Format == model.ArticleMarkdown {
+		source, target = a.Markdown, b.Markdown
+	}
+	diffs := dmp.DiffCleanupSemantic(dmp.DiffMain(source, target, false))
+	ops := make([]map[string]interface{}, len(diffs))
+	for i, d := range diffs {
+		ops[i] = map[string]interface{}{"type": int(d.Type), "text": d.Text}
+	}
+	var changed []string
+	fa, fb := Tools.StructToMap(a.Article()), Tools.StructToMap(b.Article())
+	for k, v := range fa {
+		if k != "content" && k != "markdown" && fb[k] != v {
+			changed = append(changed, k)
+		}
+	}
+	return model.ApiJson{State: true, Msg: map[string]interface{}{
+		"from":    a.Version,
+		"to":      b.Version,
+		"changed": changed,
+		"diff":    ops,
+		"html":    dmp.DiffPrettyHtml(diffs),
+	}}
+}
+
+/**
+ * 回滚到某个版本，回滚本身也会生成一个新版本
+ * 快照不包含定时发布、自动下线和slug，回滚时保持文章当前的值
+ * 回滚只恢复内容，不会把文章移回旧版本所在的节点，审核状态也只能通过流程接口修改
+ * @method RevisionRestore
+ * @param  {[type]} id   int             版本id
+ * @param  {[type]} user model.UserAdmin 操作人
+ */
+func RevisionRestore(id int, user model.UserAdmin) model.ApiJson {
+	revision := model.RevisionGet(id)
+	if revision.ID == 0 {
+		return model.ApiJson{State: false, Msg: "revision is no exist"}
+	}
+	if !ArticleAllowed(user.ID, []int{revision.Articleid}) {
+		return model.ApiJson{State: false, Msg: "permission denied"}
+	}
+	current := model.ArticleList([]int{revision.Articleid})
+	if len(current) == 0 {
+		return model.ApiJson{State: false, Msg: "article is no exist"}
+	}
+	article := revision.Article()
+	Tools.KeepFields(&article, current[0], "Nodeid", "PublishAt", "ExpireAt")
+	return ArticleUpdate(article, user)
+}
+
+//////////私有方法
+/**
+ * 保存文章快照
+ * @method revisionSave
+ */
+func revisionSave(article model.Article, user model.UserAdmin) {
+	revision := model.NewRevision(article)
+	revision.Uid = user.ID
+	revision.Username = user.Username
+	revision.Createtime = time.Now().Unix()
+	if err := model.RevisionCreate(revision); err != nil {
+		Tools.Logs("save revision error: " + err.Error())
+	}
+}
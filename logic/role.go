@@ -0,0 +1,193 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+	"strings"
+)
+
+//权限标识
+const (
+	PermAll          = "*"                //全部权限，超级管理员
+	PermArticleEdit  = "article.edit"     //编辑文章
+	PermArticlePass  = "article.pass"     //审核文章
+	PermUserAdmin    = "useradmin.manage" //管理后台用户和角色
+	PermAuditView    = "audit.view"       //查看审计日志
+	PermCache        = "cache.manage"     //查看和清除缓存
+	PermArticlePurge = "article.purge"    //彻底删除回收站中的文章
+	PermNode         = "node.manage"      //管理栏目节点
+	PermMenu         = "menu.manage"      //管理导航菜单
+	PermComment      = "comment.manage"   //审核和管理评论
+)
+
+//所有可分配的权限
+var Permissions = map[string]string{
+	PermAll:          "全部权限",
+	PermArticleEdit:  "编辑文章",
+	PermArticlePass:  "审核文章",
+	PermUserAdmin:    "管理后台用户和角色",
+	PermAuditView:    "查看审计日志",
+	PermCache:        "查看和清除缓存",
+	PermArticlePurge: "彻底删除文章",
+	PermNode:         "管理栏目",
+	PermMenu:         "管理导航菜单",
+	PermComment:      "审核和管理评论",
+}
+
+/**
+ * 获取用户的所有权限
+ * @method UserAdminPermissions
+ * @param  {[type]} uid int [description]
+ */
+func UserAdminPermissions(uid int) []string {
+	var perms []string
+	for _, role := range model.UserRoleGet(uid) {
+		for _, perm := range strings.Split(role.Permissions, ",") {
+			if perm = strings.TrimSpace(perm); perm != "" {
+				perms = append(perms, perm)
+			}
+		}
+	}
+	return perms
+}
+
+/**
+ * 判断用户是否有某个权限
+ * @method HasPermission
+ * @param  {[type]} uid  int    [description]
+ * @param  {[type]} perm string [description]
+ */
+func HasPermission(uid int, perm string) bool {
+	return len(permissionMissing(UserAdminPermissions(uid), []string{perm})) == 0
+}
+
+/**
+ * 是否是受保护的账号，受保护的账号不能删除，也不能被其他人修改角色和账号信息
+ * @method IsProtectedUserAdmin
+ * @param  {[type]} uid int [description]
+ */
+func IsProtectedUserAdmin(uid int) bool {
+	for _, id := range Config.Rbac.Protected {
+		if id == uid {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * 创建或更新role，只能授予和修改操作人自己拥有的权限
+ * @method RoleSave
+ * @param  {[type]} role     model.Role [description]
+ * @param  {[type]} operator int        操作人id
+ */
+func RoleSave(role model.Role, operator int) model.ApiJson {
+	perms, err := normalizePermissions(role.Permissions)
+	if err != "" {
+		return model.ApiJson{State: false, Msg: err}
+	}
+	role.Permissions = perms
+	roles := []model.Role{role}
+	if role.ID > 0 {
+		roles = append(roles, model.RoleList([]int{role.ID})...)
+	}
+	if msg := roleGrantCheck(operator, roles); msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	if role.ID == 0 {
+		return model.RoleCreate(role)
+	}
+	return model.RoleUpdate(role)
+}
+
+/**
+ * 删除role，不能删除包含操作人没有的权限的role
+ * @method RoleDele
+ * @param  {[type]} ids      string [description]
+ * @param  {[type]} operator int    操作人id
+ */
+func RoleDele(ids string, operator int) model.ApiJson {
+	idsInt := Tools.ParseIds(ids)
+	if len(idsInt) == 0 {
+		return model.ApiJson{State: false, Msg: "id is error"}
+	}
+	if msg := roleGrantCheck(operator, model.RoleList(idsInt)); msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	return model.RoleDele(idsInt)
+}
+
+/**
+ * 设置用户的role，新旧role的权限都必须是操作人拥有的
+ * @method UserRoleSet
+ * @param  {[type]} uid      int    [description]
+ * @param  {[type]} roleids  string [description]
+ * @param  {[type]} operator int    操作人id
+ */
+func UserRoleSet(uid int, roleids string, operator int) model.ApiJson {
+	if IsProtectedUserAdmin(uid) {
+		return model.ApiJson{State: false, Msg: "protected user's role can not be changed"}
+	}
+	ids := Tools.ParseIds(roleids)
+	roles := append(model.RoleList(ids), model.UserRoleGet(uid)...)
+	if msg := roleGrantCheck(operator, roles); msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	return model.UserRoleSet(uid, ids)
+}
+
+//////////私有方法
+//操作人必须拥有这些role的全部权限，防止给自己或他人授予更高的权限
+func roleGrantCheck(operator int, roles []model.Role) string {
+	return roleGrantMissing(UserAdminPermissions(operator), roles)
+}
+
+//held没有覆盖roles中的全部权限时返回错误信息
+func roleGrantMissing(held []string, roles []model.Role) string {
+	var perms []string
+	for _, role := range roles {
+		for _, perm := range strings.Split(role.Permissions, ",") {
+			perms = append(perms, strings.TrimSpace(perm))
+		}
+	}
+	if missing := permissionMissing(held, perms); len(missing) > 0 {
+		return "permission denied: " + strings.Join(missing, ",")
+	}
+	return ""
+}
+
+//返回perms中没有被held覆盖的权限，held包含*时覆盖全部权限
+func permissionMissing(held []string, perms []string) []string {
+	has := make(map[string]bool, len(held))
+	for _, p := range held {
+		if p == PermAll {
+			return nil
+		}
+		has[p] = true
+	}
+	var missing []string
+	for _, p := range perms {
+		if p != "" && !has[p] {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+/**
+ * 校验并整理权限列表
+ * @method normalizePermissions
+ */
+func normalizePermissions(perms string) (string, string) {
+	var arr []string
+	for _, perm := range strings.Split(perms, ",") {
+		perm = strings.TrimSpace(perm)
+		if perm == "" {
+			continue
+		}
+		if _, ok := Permissions[perm]; !ok {
+			return "", "permission " + perm + " is not exist"
+		}
+		arr = append(arr, perm)
+	}
+	return strings.Join(arr, ","), ""
+}
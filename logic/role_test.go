@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+	"reflect"
+	"testing"
+)
+
+func TestPermissionMissing(t *testing.T) {
+	tests := []struct {
+		name  string
+		held  []string
+		perms []string
+		want  []string
+	}{
+		{"all held", []string{PermArticleEdit, PermArticlePass}, []string{PermArticlePass}, nil},
+		{"one missing", []string{PermArticleEdit}, []string{PermArticleEdit, PermArticlePass}, []string{PermArticlePass}},
+		{"wildcard", []string{PermAll}, []string{PermUserAdmin}, nil},
+		{"wildcard required", []string{PermUserAdmin}, []string{PermAll}, []string{PermAll}},
+		{"empty perm ignored", nil, []string{""}, nil},
+		{"prefix is not a match", []string{"article"}, []string{PermArticleEdit}, []string{PermArticleEdit}},
+	}
+	for _, tt := range tests {
+		if got := permissionMissing(tt.held, tt.perms); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: permissionMissing() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+//只有useradmin.manage的操作人不能管理超级管理员，否则可以重置对方的密码或两步验证后冒充登录
+func TestRoleGrantMissing(t *testing.T) {
+	superadmin := model.Role{Name: "superadmin", Permissions: PermAll}
+	editor := model.Role{Name: "editor", Permissions: PermArticleEdit}
+	reviewer := model.Role{Name: "reviewer", Permissions: PermArticleEdit + ", " + PermArticlePass}
+	manager := []string{PermUserAdmin, PermArticleEdit}
+	tests := []struct {
+		name  string
+		held  []string
+		roles []model.Role
+		ok    bool
+	}{
+		{"manager on superadmin", manager, []model.Role{superadmin}, false},
+		{"manager on editor", manager, []model.Role{editor}, true},
+		{"manager on reviewer", manager, []model.Role{editor, reviewer}, false},
+		{"manager on user without role", manager, nil, true},
+		{"superadmin on superadmin", []string{PermAll}, []model.Role{superadmin}, true},
+		{"no permission on editor", nil, []model.Role{editor}, false},
+	}
+	for _, tt := range tests {
+		if got := roleGrantMissing(tt.held, tt.roles); (got == "") != tt.ok {
+			t.Errorf("%s: roleGrantMissing() = %q, want ok=%v", tt.name, got, tt.ok)
+		}
+	}
+}
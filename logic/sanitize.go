@@ -0,0 +1,126 @@
+package logic
+
+import (
+	"github.com/microcosm-cc/bluemonday"
+	"pizzaCmsApi/model"
+	"regexp"
+	"sync"
+)
+
+//内联样式只允许普通的属性值和rgb颜色，不允许url()、expression()和css转义
+var sanitizeStyle = regexp.MustCompile(`^(?:[\w\s\-:;,.#%'"!/]|rgba?\([\d\s,.%]*\))*$`)
+
+var sanitizeCodeClass = regexp.MustCompile(`^language-[\w+#-]+$`)
+
+var (
+	sanitizeOnce    sync.Once
+	sanitizeArticle *bluemonday.Policy
+	sanitizeComment *bluemonday.Policy
+)
+
+/**
+ * 过滤文章内容，在UGC规则的基础上允许配置中的标签、属性和内联样式
+ * @method SanitizeArticle
+ * @param  {[type]} content string [description]
+ */
+func SanitizeArticle(content string) string {
+	sanitizeOnce.Do(sanitizeInit)
+	return sanitizeArticle.Sanitize(content)
+}
+
+/**
+ * 过滤评论内容，只允许配置中的少量标签
+ * @method SanitizeComment
+ * @param  {[type]} content string [description]
+ */
+func SanitizeComment(content string) string {
+	sanitizeOnce.Do(sanitizeInit)
+	return sanitizeComment.Sanitize(content)
+}
+
+/**
+ * 按当前规则过滤已有的文章和评论，只更新有变化的数据，可以重复执行
+ * @method SanitizeMigrate
+ */
+func SanitizeMigrate() error {
+	ids, err := model.ArticleIds()
+	if err != nil {
+		return err
+	}
+	var changed []int
+	for i := 0; i < len(ids); i += 100 {
+		end := i + 100
+		if end > len(ids) {
+			end = len(ids)
+		}
+		for _, article := range model.ArticleList(ids[i:end]) {
+			//markdown格式的文章从原文重新渲染
+			sanitized, msg := articleContent(article)
+			if msg != "" {
+				Tools.Logs("sanitize article " + Tools.ParseString(article.ID) + ": " + msg)
+				continue
+			}
+			if sanitized.Content == article.Content {
+				continue
+			}
+			if err := model.ArticleContentSet(article.ID, sanitized.Content); err != nil {
+				return err
+			}
+			changed = append(changed, article.ID)
+		}
+	}
+	SearchSync(changed)
+	CacheInvalidateArticles(changed)
+	Tools.Logs("sanitize articles: " + Tools.ParseString(len(changed)))
+
+	ids, err = model.CommentIds()
+	if err != nil {
+		return err
+	}
+	count := 0
+	for i := 0; i < len(ids); i += 100 {
+		end := i + 100
+		if end > len(ids) {
+			end = len(ids)
+		}
+		for _, comment := range model.CommentList(ids[i:end]) {
+			content := SanitizeComment(comment.Content)
+			if content == comment.Content {
+				continue
+			}
+			if err := model.CommentContentSet(comment.Id, content); err != nil {
+				return err
+			}
+			count++
+		}
+	}
+	Tools.Logs("sanitize comments: " + Tools.ParseString(count))
+	return nil
+}
+
+//////////私有方法
+func sanitizeInit() {
+	article := bluemonday.UGCPolicy()
+	if len(Config.Sanitize.Elements) > 0 {
+		article.AllowElements(Config.Sanitize.Elements...)
+	}
+	if len(Config.Sanitize.Attrs) > 0 {
+		article.AllowAttrs(Config.Sanitize.Attrs...).Globally()
+	}
+	//markdown代码块的语言
+	article.AllowAttrs("class").Matching(sanitizeCodeClass).OnElements("code")
+	if Config.Sanitize.Style {
+		article.AllowAttrs("style").Matching(sanitizeStyle).Globally()
+	}
+	sanitizeArticle = article
+
+	if len(Config.Sanitize.Comment) == 0 {
+		sanitizeComment = bluemonday.StrictPolicy()
+		return
+	}
+	comment := bluemonday.NewPolicy()
+	comment.AllowStandardURLs()
+	comment.AllowElements(Config.Sanitize.Comment...)
+	comment.AllowAttrs("href").OnElements("a")
+	sanitizeComment = comment
+}
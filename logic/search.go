@@ -0,0 +1,210 @@
+package logic
+
+import (
+	"math"
+	"pizzaCmsApi/model"
+	"strings"
+)
+
+//各字段的权重，标题命中比正文命中更靠前
+var searchFieldWeights = []struct {
+	Weight int
+	Text   func(model.Article) string
+}{
+	{10, func(a model.Article) string { return a.Title }},
+	{6, func(a model.Article) string { return a.Tags }},
+	{3, func(a model.Article) string { return a.Brief }},
+	{1, func(a model.Article) string { return Tools.StripTags(a.Content) }},
+}
+
+const (
+	searchSnippetLength = 120 //摘要片段的长度
+	searchTotalCache    = 300 //索引文章数的缓存秒数
+)
+
+type SearchResult struct {
+	model.ArticleResults
+	Score     float64 `json:"score"`
+	Highlight string  `json:"highlight"` //高亮的标题
+	Snippet   string  `json:"snippet"`   //高亮的正文片段
+}
+
+/**
+ * 建立一篇文章的索引
+ * @method SearchIndexArticle
+ * @param  {[type]} article model.Article [description]
+ */
+func SearchIndexArticle(article model.Article) error {
+	weights := map[string]int{}
+	for _, field := range searchFieldWeights {
+		for _, term := range Tools.Segment(field.Text(article)) {
+			weights[term] += field.Weight
+		}
+	}
+	if err := model.SearchIndexSave(article.ID, weights); err != nil {
+		return err
+	}
+	return model.SearchSuggestSave(article.ID, searchSuggests(article))
+}
+
+/**
+ * 删除文章的索引和联想词
+ * @method SearchRemove
+ * @param  {[type]} ids []int [description]
+ */
+func SearchRemove(ids []int) {
+	model.SearchIndexDele(ids)
+	model.SearchSuggestDele(ids)
+}
+
+/**
+ * 按id重建文章的索引，文章不存在时删除索引
+ * @method SearchSync
+ * @param  {[type]} ids []int [description]
+ */
+func SearchSync(ids []int) {
+	articles := model.ArticleList(ids)
+	exist := map[int]bool{}
+	for _, article := range articles {
+		if article.TrashAt > 0 { //回收站中的文章不索引
+			continue
+		}
+		exist[article.ID] = true
+		if err := SearchIndexArticle(article); err != nil {
+			Tools.Logs("search index " + Tools.ParseString(article.ID) + ": " + err.Error())
+		}
+	}
+	var removed []int
+	for _, id := range ids {
+		if !exist[id] {
+			removed = append(removed, id)
+		}
+	}
+	if len(removed) > 0 {
+		SearchRemove(removed)
+	}
+}
+
+/**
+ * 重建全部文章的索引
+ * @method SearchReindex
+ */
+func SearchReindex() error {
+	ids, err := model.ArticleIds()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(ids); i += 100 {
+		end := i + 100
+		if end > len(ids) {
+			end = len(ids)
+		}
+		SearchSync(ids[i:end])
+	}
+	return nil
+}
+
+/**
+ * 全文搜索，所有词都命中的文章优先，没有时返回命中任意词的文章
+ * @method Search
+ * @param  {[type]} kw     string 关键词
+ * @param  {[type]} nodeid int    节点id，0表示不限
+ * @param  {[type]} pass   int    审核状态，-1表示不限
+ * @param  {[type]} start  int64  创建时间起
+ * @param  {[type]} end    int64  创建时间止
+ * @param  {[type]} cp     int    [description]
+ * @param  {[type]} mp     int    [description]
+ * @param  {[type]} public bool   只搜索前台可见的文章
+ * @param  {[type]} uid    int    当前管理员id，有节点授权时授权节点以外只搜索前台可见的文章
+ */
+func Search(kw string, nodeid int, pass int, start int64, end int64, cp int, mp int, public bool, uid int) model.ApiJson {
+	terms := Tools.SegmentQuery(kw)
+	var scope []string
+	if !public {
+		if paths, limited := NodeScope(uid); limited {
+			scope = paths
+		}
+	}
+	matched, count := searchMatch(terms, nodeid, pass, start, end, public, scope, (cp-1)*mp, mp)
+	if count > 0 && cp == 1 {
+		SearchLog(kw)
+	}
+	ids := make([]int, len(matched))
+	scores := map[int]float64{}
+	for i, match := range matched {
+		ids[i] = match.Articleid
+		scores[match.Articleid] = match.Score
+	}
+	articles := model.ArticleResultsList(ids)
+	if public {
+		articles = model.ArticlesPublic(articles)
+	}
+	results := make([]SearchResult, len(articles))
+	for i, article := range articles {
+		results[i] = SearchResult{
+			ArticleResults: article,
+			Score:          scores[article.ID],
+			Highlight:      Tools.Highlight(article.Title, terms, 0),
+			Snippet:        Tools.Highlight(searchSnippetText(article.Article), terms, searchSnippetLength),
+		}
+	}
+	return model.ApiJson{State: true, Msg: results, Count: count}
+}
+
+//////////私有方法
+func searchSnippetText(article model.Article) string {
+	text := Tools.StripTags(article.Content)
+	if strings.TrimSpace(text) == "" {
+		return article.Brief
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+//按相关度分页返回命中的文章和命中总数，所有词都命中的文章优先，没有时返回命中任意词的文章
+func searchMatch(terms []string, nodeid int, pass int, start int64, end int64, public bool, scope []string, offset int, limit int) ([]model.SearchScore, int) {
+	if len(terms) == 0 {
+		return nil, 0
+	}
+	df := model.SearchIndexDf(terms)
+	if len(df) == 0 {
+		return nil, 0
+	}
+	total := searchIndexTotal()
+	idf := map[string]float64{}
+	for term, n := range df {
+		idf[term] = math.Log(1 + float64(total)/float64(n))
+	}
+	if matched, count := model.SearchIndexMatch(idf, true, nodeid, pass, start, end, public, scope, offset, limit); count > 0 {
+		return matched, count
+	}
+	return model.SearchIndexMatch(idf, false, nodeid, pass, start, end, public, scope, offset, limit)
+}
+
+//已建立索引的文章数，短时间缓存
+func searchIndexTotal() int {
+	var total int
+	err := Redis.Remember("search.total", "search:total", searchTotalCache, nil, &total, func() (interface{}, error) {
+		return model.SearchIndexCount(), nil
+	})
+	if err != nil {
+		return model.SearchIndexCount()
+	}
+	return total
+}
+
+//文章的联想词：标题和每个标签
+func searchSuggests(article model.Article) []model.SearchSuggest {
+	var suggests []model.SearchSuggest
+	if title := strings.TrimSpace(article.Title); title != "" {
+		suggests = append(suggests, model.SearchSuggest{Word: title, Initials: Tools.PinyinInitials(title), Kind: "title"})
+	}
+	seen := map[string]bool{}
+	for _, tag := range TagSplit(article.Tags) {
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		suggests = append(suggests, model.SearchSuggest{Word: tag, Initials: Tools.PinyinInitials(tag), Kind: "tag"})
+	}
+	return suggests
+}
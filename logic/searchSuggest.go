@@ -0,0 +1,207 @@
+package logic
+
+import (
+	redigo "github.com/garyburd/redigo/redis"
+	"pizzaCmsApi/model"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	searchHotPrefix    = "search:hot:"      //每天的搜索词，search:hot:20160312
+	searchHotSumPrefix = "search:hot:days:" //最近几天合并后的搜索词，短时间缓存
+	searchHotKeep      = 31                 //每天的搜索词保留天数
+	searchHotCache     = 60                 //合并结果的缓存秒数
+	searchSuggestCache = 60                 //联想结果和搜索词命中数的缓存秒数
+	searchTagsCache    = 600                //拼写纠正候选标签的缓存秒数
+)
+
+type HotKeyword struct {
+	Keyword string `json:"keyword"`
+	Score   int    `json:"score"` //搜索次数
+}
+
+type SearchSuggestion struct {
+	Words      []model.SearchSuggestResult `json:"words"`      //标题和标签补全
+	DidYouMean []string                    `json:"didyoumean"` //没有结果时的纠正建议
+}
+
+/**
+ * 记录一次有结果的搜索，用于热门搜索词
+ * @method SearchLog
+ * @param  {[type]} kw string [description]
+ */
+func SearchLog(kw string) {
+	kw = searchNormalize(kw)
+	if kw == "" {
+		return
+	}
+	key := searchHotPrefix + time.Now().Format("20060102")
+	Redis.Do("ZINCRBY", key, 1, kw)
+	Redis.Do("EXPIRE", key, searchHotKeep*86400)
+}
+
+/**
+ * 最近几天的热门搜索词
+ * @method SearchHot
+ * @param  {[type]} days  int [description]
+ * @param  {[type]} limit int [description]
+ */
+func SearchHot(days int, limit int) []HotKeyword {
+	hot := []HotKeyword{}
+	key := searchHotSumPrefix + Tools.ParseString(days)
+	if exists, _ := redigo.Bool(Redis.Do("EXISTS", key)); !exists {
+		args := []interface{}{key, days}
+		now := time.Now()
+		for i := 0; i < days; i++ {
+			args = append(args, searchHotPrefix+now.AddDate(0, 0, -i).Format("20060102"))
+		}
+		if _, err := Redis.Do("ZUNIONSTORE", args...); err != nil {
+			return hot
+		}
+		Redis.Do("EXPIRE", key, searchHotCache)
+	}
+	values, err := redigo.Strings(Redis.Do("ZREVRANGE", key, 0, limit-1, "WITHSCORES"))
+	if err != nil {
+		return hot
+	}
+	for i := 0; i+1 < len(values); i += 2 {
+		hot = append(hot, HotKeyword{Keyword: values[i], Score: Tools.ParseInt(values[i+1], 0)})
+	}
+	return hot
+}
+
+/**
+ * 搜索联想：按前缀和拼音首字母补全标题和标签，没有搜索结果时给出纠正建议，结果短时间缓存
+ * @method SearchSuggest
+ * @param  {[type]} kw     string [description]
+ * @param  {[type]} limit  int    [description]
+ * @param  {[type]} public bool   只包含前台可见的文章
+ */
+func SearchSuggest(kw string, limit int, public bool) model.ApiJson {
+	kw = strings.TrimSpace(kw)
+	var suggestion SearchSuggestion
+	key := "search:suggest:" + Tools.Sha256(kw)[:16] + ":" + Tools.ParseString(limit)
+	if public {
+		key += ":public"
+	}
+	err := Redis.Remember("search.suggest", key, searchSuggestCache, nil, &suggestion, func() (interface{}, error) {
+		return searchSuggestLoad(kw, limit, public), nil
+	})
+	if err != nil {
+		suggestion = searchSuggestLoad(kw, limit, public)
+	}
+	if suggestion.Words == nil {
+		suggestion.Words = []model.SearchSuggestResult{}
+	}
+	if suggestion.DidYouMean == nil {
+		suggestion.DidYouMean = []string{}
+	}
+	return model.ApiJson{State: true, Msg: suggestion}
+}
+
+/**
+ * 拼写纠正：从热门搜索词和标签中找编辑距离最近并且有结果的词
+ * @method SearchDidYouMean
+ * @param  {[type]} kw     string [description]
+ * @param  {[type]} public bool   [description]
+ */
+func SearchDidYouMean(kw string, public bool) []string {
+	kw = searchNormalize(kw)
+	result := []string{}
+	if kw == "" {
+		return result
+	}
+	maxDistance := 1
+	if len([]rune(kw)) > 4 {
+		maxDistance = 2
+	}
+	type candidate struct {
+		Word     string
+		Distance int
+		Rank     int
+	}
+	var candidates []candidate
+	seen := map[string]bool{kw: true}
+	add := func(word string, rank int) {
+		word = searchNormalize(word)
+		if seen[word] {
+			return
+		}
+		seen[word] = true
+		if d := Tools.Levenshtein(kw, word); d <= maxDistance {
+			candidates = append(candidates, candidate{word, d, rank})
+		}
+	}
+	for i, hot := range SearchHot(7, 200) {
+		add(hot.Keyword, i)
+	}
+	for i, tag := range searchSuggestTags() {
+		add(tag, i)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Distance != candidates[j].Distance {
+			return candidates[i].Distance < candidates[j].Distance
+		}
+		return candidates[i].Rank < candidates[j].Rank
+	})
+	for _, c := range candidates {
+		if searchHits(c.Word, public) > 0 {
+			result = append(result, c.Word)
+			if len(result) >= 3 {
+				break
+			}
+		}
+	}
+	return result
+}
+
+//////////私有方法
+func searchSuggestLoad(kw string, limit int, public bool) SearchSuggestion {
+	suggestion := SearchSuggestion{
+		Words:      model.SearchSuggestFind(kw, limit, public),
+		DidYouMean: []string{},
+	}
+	if searchHits(kw, public) == 0 {
+		suggestion.DidYouMean = SearchDidYouMean(kw, public)
+	}
+	return suggestion
+}
+
+//搜索词命中的文章数，短时间缓存，拼写纠正时每个候选词都要用到
+func searchHits(kw string, public bool) int {
+	var count int
+	key := "search:hits:" + Tools.Sha256(kw)[:16]
+	if public {
+		key += ":public"
+	}
+	err := Redis.Remember("search.hits", key, searchSuggestCache, nil, &count, func() (interface{}, error) {
+		_, n := searchMatch(Tools.SegmentQuery(kw), 0, -1, 0, 0, public, nil, 0, 0)
+		return n, nil
+	})
+	if err != nil {
+		_, count = searchMatch(Tools.SegmentQuery(kw), 0, -1, 0, 0, public, nil, 0, 0)
+	}
+	return count
+}
+
+//常用标签，拼写纠正的候选词
+func searchSuggestTags() []string {
+	var tags []string
+	err := Redis.Remember("search.tags", "search:tags", searchTagsCache, nil, &tags, func() (interface{}, error) {
+		return model.SearchSuggestTags(1000), nil
+	})
+	if err != nil {
+		return model.SearchSuggestTags(1000)
+	}
+	return tags
+}
+
+func searchNormalize(kw string) string {
+	kw = strings.ToLower(strings.Join(strings.Fields(kw), " "))
+	if len([]rune(kw)) > 50 {
+		return ""
+	}
+	return kw
+}
@@ -0,0 +1,102 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+	"strings"
+)
+
+const (
+	sessionPrefix     = "session:"      //session:token => uid
+	sessionUserPrefix = "session:user:" //session:user:uid => set(token)，用于吊销用户的全部会话
+)
+
+/**
+ * 为管理员创建会话token，token格式为 uid.随机串.签名
+ * @method SessionCreate
+ * @param  {[type]} uid int [description]
+ */
+func SessionCreate(uid int) (string, error) {
+	raw := Tools.ParseString(uid) + "." + Tools.RandomHex(16)
+	token := Tools.TokenSign(raw, Config.Session.Secret)
+	ex := Tools.ParseString(Config.Session.Expire)
+	if _, err := Redis.SetString(sessionPrefix+token, Tools.ParseString(uid), ex); err != nil {
+		return "", err
+	}
+	userKey := sessionUserPrefix + Tools.ParseString(uid)
+	Redis.Do("SADD", userKey, token)
+	Redis.Do("EXPIRE", userKey, ex)
+	return token, nil
+}
+
+/**
+ * 校验token，成功返回对应的管理员
+ * @method SessionCheck
+ * @param  {[type]} token string [description]
+ */
+func SessionCheck(token string) (model.UserAdmin, bool) {
+	var user model.UserAdmin
+	signed, ok := Tools.TokenVerify(token, Config.Session.Secret)
+	if !ok { //签名不对的token不用再查redis
+		return user, false
+	}
+	uid, err := Redis.GetString(sessionPrefix + token)
+	if err != nil || uid != signed {
+		return user, false
+	}
+	user = model.UserAdminFind(Tools.ParseInt(uid, 0))
+	if user.ID == 0 { //用户已被删除
+		SessionDestroy(token)
+		return user, false
+	}
+	return user, true
+}
+
+/**
+ * 注销token
+ * @method SessionDestroy
+ * @param  {[type]} token string [description]
+ */
+func SessionDestroy(token string) model.ApiJson {
+	_, err := Redis.Del(sessionPrefix + token)
+	if err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	arr := strings.Split(token, ".")
+	Redis.Do("SREM", sessionUserPrefix+arr[0], token)
+	return model.ApiJson{State: true}
+}
+
+/**
+ * 注销用户的全部token，用于删除用户或修改密码
+ * @method SessionDestroyUser
+ * @param  {[type]} uid int [description]
+ */
+func SessionDestroyUser(uid int) {
+	userKey := sessionUserPrefix + Tools.ParseString(uid)
+	tokens, err := Redis.SMembers(userKey)
+	if err != nil {
+		return
+	}
+	for _, token := range tokens {
+		Redis.Del(sessionPrefix + token)
+	}
+	Redis.Del(userKey)
+}
+
+/**
+ * 刷新token，旧token立即失效
+ * @method SessionRefresh
+ * @param  {[type]} token string [description]
+ */
+func SessionRefresh(token string) model.ApiJson {
+	user, ok := SessionCheck(token)
+	if !ok {
+		return model.ApiJson{State: false, Msg: "token is invalid"}
+	}
+	newToken, err := SessionCreate(user.ID)
+	if err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	SessionDestroy(token)
+	return model.ApiJson{State: true, Msg: map[string]interface{}{"token": newToken, "expire": Config.Session.Expire}}
+}
@@ -0,0 +1,90 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+	"regexp"
+	"strings"
+)
+
+const slugMaxLength = 80 //自动生成的slug最大长度
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+/**
+ * 根据标题生成唯一的slug，中文转为拼音，如"马航残片"生成"ma-hang-can-pian"，重复时加数字后缀
+ * @method SlugGenerate
+ * @param  {[type]} title     string [description]
+ * @param  {[type]} articleid int    当前文章id，新文章传0
+ */
+func SlugGenerate(title string, articleid int) string {
+	base := Tools.Pinyin(title, "-")
+	for len(base) > slugMaxLength {
+		i := strings.LastIndex(base, "-")
+		if i <= 0 {
+			base = base[:slugMaxLength]
+			break
+		}
+		base = base[:i]
+	}
+	if base == "" {
+		base = "article-" + Tools.RandomHex(4)
+	}
+	slug := base
+	for i := 2; model.ArticleSlugExist(slug, articleid); i++ {
+		slug = base + "-" + Tools.ParseString(i)
+	}
+	return slug
+}
+
+/**
+ * 检查slug格式以及是否被其他文章使用，返回错误信息
+ * @method SlugCheck
+ * @param  {[type]} slug      string [description]
+ * @param  {[type]} articleid int    当前文章id，新文章传0
+ */
+func SlugCheck(slug string, articleid int) string {
+	if len(slug) > 100 || !slugPattern.MatchString(slug) {
+		return "slug can only contain lowercase letters, numbers and -"
+	}
+	if model.ArticleSlugExist(slug, articleid) {
+		return "slug is exist"
+	}
+	return ""
+}
+
+/**
+ * 根据slug获取文章，旧的slug返回文章当前的slug用于跳转
+ * @method ArticleGetBySlug
+ * @param  {[type]} slug   string          [description]
+ * @param  {[type]} public bool            前台读取，只返回已发布的文章
+ * @param  {[type]} user   model.UserAdmin 后台读取时的当前管理员，只能读取授权节点下的文章
+ */
+func ArticleGetBySlug(slug string, public bool, user model.UserAdmin) (model.ApiJson, string) {
+	id, redirect := model.ArticleFindBySlug(slug)
+	if id == 0 {
+		return model.ApiJson{State: false, Msg: "article is no exist"}, ""
+	}
+	var result model.ApiJson
+	if public {
+		result = ArticleGetPublic(id)
+	} else {
+		result = ArticleGet(id, user)
+	}
+	if article, ok := result.Msg.(model.Article); ok && result.State && redirect {
+		return result, article.Slug
+	}
+	return result, ""
+}
+
+/**
+ * 给没有slug的文章生成slug，用于升级已有数据
+ * @method SlugMigrate
+ */
+func SlugMigrate() error {
+	for _, article := range model.ArticleList(model.ArticleWithoutSlug()) {
+		if err := model.ArticleSlugSet(article.ID, SlugGenerate(article.Title, article.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
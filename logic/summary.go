@@ -0,0 +1,80 @@
+package logic
+
+import (
+	"errors"
+	"pizzaCmsApi/model"
+	"strings"
+)
+
+const briefMax = 255 //brief字段的最大字数
+
+/**
+ * 给已有文章计算字数和阅读时间，摘要为空的同时生成摘要，可以重复执行
+ * 单篇文章写入失败时记录日志并继续，已修改的文章都会更新索引和缓存
+ * @method SummaryMigrate
+ */
+func SummaryMigrate() error {
+	ids, err := model.ArticleIds()
+	if err != nil {
+		return err
+	}
+	var changed, failed []int
+	for i := 0; i < len(ids); i += 100 {
+		end := i + 100
+		if end > len(ids) {
+			end = len(ids)
+		}
+		for _, article := range model.ArticleList(ids[i:end]) {
+			updated := articleSummary(article)
+			if updated.Brief == article.Brief && updated.Words == article.Words && updated.Readtime == article.Readtime {
+				continue
+			}
+			if err := model.ArticleStatsSet(article.ID, updated.Brief, updated.Words, updated.Readtime); err != nil {
+				Tools.Logs("summary article " + Tools.ParseString(article.ID) + ": " + err.Error())
+				failed = append(failed, article.ID)
+				continue
+			}
+			changed = append(changed, article.ID)
+		}
+	}
+	SearchSync(changed)
+	CacheInvalidateArticles(changed)
+	Tools.Logs("summary articles: " + Tools.ParseString(len(changed)))
+	if len(failed) > 0 {
+		return errors.New("summary failed articles: " + Tools.ParseString(len(failed)))
+	}
+	return nil
+}
+
+//////////私有方法
+//按content计算字数和阅读时间，开启自动摘要时给空摘要生成摘要
+//content是过滤后的html，markdown文章是渲染后的html，摘要按块级标签分句并去掉代码块
+func articleSummary(article model.Article) model.Article {
+	text := Tools.StripTags(article.Content)
+	cjk, words := Tools.WordCount(text)
+	article.Words = cjk + words
+	article.Readtime = 0
+	if article.Words > 0 {
+		cjkSpeed, wordSpeed := Config.Article.CjkSpeed, Config.Article.WordSpeed
+		if cjkSpeed <= 0 {
+			cjkSpeed = 400
+		}
+		if wordSpeed <= 0 {
+			wordSpeed = 200
+		}
+		//不足一分钟的按一分钟算
+		seconds := cjk*60/cjkSpeed + words*60/wordSpeed
+		article.Readtime = (seconds + 59) / 60
+		if article.Readtime == 0 {
+			article.Readtime = 1
+		}
+	}
+	if Config.Article.AutoBrief && strings.TrimSpace(article.Brief) == "" {
+		length := Config.Article.BriefLength
+		if length <= 0 || length > briefMax {
+			length = briefMax
+		}
+		article.Brief = Tools.Summary(Tools.SummaryText(article.Content), length)
+	}
+	return article
+}
@@ -0,0 +1,214 @@
+package logic
+
+import (
+	"math"
+	"pizzaCmsApi/model"
+	"strings"
+)
+
+const (
+	tagMaxLength  = 30  //单个tag的最大字数
+	tagsMaxLength = 100 //文章tags字段的最大字节数
+)
+
+type TagCloudItem struct {
+	model.Tag
+	Level int `json:"level"` //1-5，文章数越多级别越高
+}
+
+/**
+ * 拆分tags字符串，支持空格、逗号、顿号、分号分隔，去重并限制长度
+ * @method TagSplit
+ * @param  {[type]} tags string [description]
+ */
+func TagSplit(tags string) []string {
+	var names []string
+	seen := map[string]bool{}
+	length := 0
+	for _, name := range strings.FieldsFunc(tags, tagSeparator) {
+		if r := []rune(name); len(r) > tagMaxLength {
+			name = string(r[:tagMaxLength])
+		}
+		if seen[name] {
+			continue
+		}
+		if length > 0 {
+			length++
+		}
+		if length+len(name) > tagsMaxLength {
+			break
+		}
+		length += len(name)
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+/**
+ * 按tags字符串设置文章的tag
+ * @method ArticleTagSave
+ * @param  {[type]} articleid int    [description]
+ * @param  {[type]} tags      string [description]
+ */
+func ArticleTagSave(articleid int, tags string) error {
+	return model.ArticleTagSet(articleid, TagSplit(tags))
+}
+
+/**
+ * 创建tag
+ * @method TagCreate
+ * @param  {[type]} tag model.Tag [description]
+ */
+func TagCreate(tag model.Tag) model.ApiJson {
+	name, err := tagName(tag.Name)
+	if err != "" {
+		return model.ApiJson{State: false, Msg: err}
+	}
+	tag.Name = name
+	return model.TagCreate(tag)
+}
+
+/**
+ * 重命名tag，使用该tag的文章会重新建立搜索索引
+ * @method TagRename
+ * @param  {[type]} id   int    [description]
+ * @param  {[type]} name string [description]
+ */
+func TagRename(id int, name string) model.ApiJson {
+	name, msg := tagName(name)
+	if msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	articleids, err := model.TagRename(id, name)
+	if err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	SearchSync(articleids)
+	CacheInvalidateArticles(articleids)
+	return model.ApiJson{State: true}
+}
+
+/**
+ * 删除tag
+ * @method TagDele
+ * @param  {[type]} ids string [description]
+ */
+func TagDele(ids string) model.ApiJson {
+	idsInt := Tools.ParseIds(ids)
+	if len(idsInt) == 0 {
+		return model.ApiJson{State: false, Msg: "id is error"}
+	}
+	articleids, err := model.TagDele(idsInt)
+	if err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	SearchSync(articleids)
+	CacheInvalidateArticles(articleids)
+	return model.ApiJson{State: true}
+}
+
+/**
+ * 合并tag
+ * @method TagMerge
+ * @param  {[type]} ids string 被合并的tag id，逗号隔开
+ * @param  {[type]} to  int    目标tag id
+ */
+func TagMerge(ids string, to int) model.ApiJson {
+	var from []int
+	for _, id := range Tools.ParseIds(ids) {
+		if id != to {
+			from = append(from, id)
+		}
+	}
+	if len(from) == 0 {
+		return model.ApiJson{State: false, Msg: "id is error"}
+	}
+	articleids, err := model.TagMerge(from, to)
+	if err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	SearchSync(articleids)
+	CacheInvalidateArticles(articleids)
+	return model.ApiJson{State: true}
+}
+
+/**
+ * 按tag名称获取文章
+ * @method TagArticles
+ * @param  {[type]} name   string [description]
+ * @param  {[type]} cp     int    [description]
+ * @param  {[type]} mp     int    [description]
+ * @param  {[type]} public bool   [description]
+ */
+func TagArticles(name string, cp int, mp int, public bool) model.ApiJson {
+	tag := model.TagFind(strings.TrimSpace(name))
+	if tag.ID == 0 {
+		return model.ApiJson{State: false, Msg: "tag is no exist"}
+	}
+	return model.TagArticles(tag.ID, cp, mp, public)
+}
+
+/**
+ * 标签云，按文章数的对数分为5级
+ * @method TagCloud
+ * @param  {[type]} limit  int  [description]
+ * @param  {[type]} public bool [description]
+ */
+func TagCloud(limit int, public bool) []TagCloudItem {
+	tags := model.TagCloud(limit, public)
+	items := make([]TagCloudItem, len(tags))
+	if len(tags) == 0 {
+		return items
+	}
+	max := math.Log(float64(tags[0].Count))
+	min := math.Log(float64(tags[len(tags)-1].Count))
+	for i, tag := range tags {
+		level := 5
+		if max > min {
+			level = 1 + int(4*(math.Log(float64(tag.Count))-min)/(max-min)+0.5)
+		}
+		items[i] = TagCloudItem{Tag: tag, Level: level}
+	}
+	return items
+}
+
+/**
+ * 把文章tags字段拆分到tag表，用于升级已有数据，可以重复执行
+ * @method TagMigrate
+ */
+func TagMigrate() error {
+	ids, err := model.ArticleIds()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(ids); i += 100 {
+		end := i + 100
+		if end > len(ids) {
+			end = len(ids)
+		}
+		for _, article := range model.ArticleList(ids[i:end]) {
+			if err := ArticleTagSave(article.ID, article.Tags); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//////////私有方法
+func tagSeparator(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == ',' || r == '，' || r == '、' || r == ';' || r == '；'
+}
+
+//整理tag名称，名称中不能包含分隔符
+func tagName(name string) (string, string) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > tagMaxLength {
+		return "", "tag name length must be 1-30"
+	}
+	if strings.IndexFunc(name, tagSeparator) >= 0 {
+		return "", "tag name can not contain space or comma"
+	}
+	return name, ""
+}
@@ -0,0 +1,200 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+	"strings"
+)
+
+const (
+	totpPendingPrefix = "totp:pending:" //totp:pending:uid => 待确认的密钥
+	totpTicketPrefix  = "totp:ticket:"  //totp:ticket:ticket => uid，密码校验通过后等待输入验证码
+	totpTriesPrefix   = "totp:tries:"   //ticket的尝试次数
+	totpUsedPrefix    = "totp:used:"    //totp:used:uid => 最后使用的时间步，防重放
+	totpTicketExpire  = 300             //ticket有效期，单位秒
+	totpMaxTries      = 5               //每个ticket最多尝试次数
+	totpRecoveryCount = 10              //恢复码数量
+)
+
+/**
+ * 开始绑定两步验证，生成待确认的密钥
+ * @method TotpEnroll
+ * @param  {[type]}   user model.UserAdmin [description]
+ */
+func TotpEnroll(user model.UserAdmin) model.ApiJson {
+	if user.Totp == 1 {
+		return model.ApiJson{State: false, Msg: "totp is already enabled"}
+	}
+	secret := Tools.TotpSecret()
+	if _, err := Redis.SetString(totpPendingPrefix+Tools.ParseString(user.ID), secret, "600"); err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	return model.ApiJson{State: true, Msg: map[string]interface{}{"secret": secret, "uri": Tools.TotpURI(Config.Totp.Issuer, user.Username, secret)}}
+}
+
+/**
+ * 用身份验证器上的验证码确认绑定，返回恢复码，恢复码只显示这一次
+ * @method TotpEnable
+ * @param  {[type]}   uid  int    [description]
+ * @param  {[type]}   code string [description]
+ */
+func TotpEnable(uid int, code string) model.ApiJson {
+	key := totpPendingPrefix + Tools.ParseString(uid)
+	secret, err := Redis.GetString(key)
+	if err != nil || secret == "" {
+		return model.ApiJson{State: false, Msg: "please enroll first"}
+	}
+	step, ok := Tools.TotpVerify(secret, code, 1)
+	if !ok {
+		return model.ApiJson{State: false, Msg: "code is error"}
+	}
+	codes, hashes := totpRecoveryCodes()
+	if err := model.UserAdminSetTotp(uid, secret, strings.Join(hashes, ",")); err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	Redis.Del(key)
+	Redis.SetString(totpUsedPrefix+Tools.ParseString(uid), Tools.ParseString(int(step)), "120")
+	return model.ApiJson{State: true, Msg: map[string]interface{}{"recovery": codes}}
+}
+
+/**
+ * 关闭两步验证，需要验证码或者恢复码，失败计入登录失败次数
+ * @method TotpDisable
+ * @param  {[type]}    uid  int    [description]
+ * @param  {[type]}    code string [description]
+ * @param  {[type]}    ip   string [description]
+ */
+func TotpDisable(uid int, code string, ip string) model.ApiJson {
+	user := model.UserAdminFull(uid)
+	if user.Totp != 1 {
+		return model.ApiJson{State: false, Msg: "totp is not enabled"}
+	}
+	if locked, ttl := LoginLocked(user.Username, ip); locked {
+		return model.ApiJson{State: false, Msg: map[string]interface{}{"msg": "account is locked", "locked": true, "retry": ttl}}
+	}
+	if !totpCheck(user, code) {
+		LoginFail(user.Username, ip)
+		return model.ApiJson{State: false, Msg: "code is error"}
+	}
+	return totpClear(uid)
+}
+
+/**
+ * 管理员重置两步验证，用于丢失设备，重置后注销该用户的全部会话
+ * 受保护的账号只能自己重置，重置其他人时操作人必须拥有对方的全部权限
+ * @method TotpReset
+ * @param  {[type]}  uid      int [description]
+ * @param  {[type]}  operator int 操作人id
+ */
+func TotpReset(uid int, operator int) model.ApiJson {
+	if msg := userAdminManageCheck(uid, operator); msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	result := totpClear(uid)
+	if result.State {
+		SessionDestroyUser(uid)
+	}
+	return result
+}
+
+/**
+ * 密码校验通过后生成ticket，等待第二步验证
+ * @method TotpTicket
+ * @param  {[type]}   uid int [description]
+ */
+func TotpTicket(uid int) (string, error) {
+	ticket := Tools.RandomHex(16)
+	_, err := Redis.SetString(totpTicketPrefix+ticket, Tools.ParseString(uid), Tools.ParseString(totpTicketExpire))
+	return ticket, err
+}
+
+/**
+ * 登录第二步，校验ticket和验证码(或者恢复码)，成功后创建会话
+ * 验证码错误和密码错误一样计入用户名和ip的失败次数，超过后锁定，重新登录拿新的ticket也不能绕过
+ * @method TotpLogin
+ * @param  {[type]}  ticket string [description]
+ * @param  {[type]}  code   string [description]
+ * @param  {[type]}  ip     string [description]
+ */
+func TotpLogin(ticket string, code string, ip string) model.ApiJson {
+	uid, err := Redis.GetString(totpTicketPrefix + ticket)
+	if err != nil || uid == "" {
+		return model.ApiJson{State: false, Msg: "ticket is invalid"}
+	}
+	user := model.UserAdminFull(Tools.ParseInt(uid, 0))
+	if user.ID == 0 || user.Totp != 1 {
+		Redis.Del(totpTicketPrefix + ticket)
+		return model.ApiJson{State: false, Msg: "ticket is invalid"}
+	}
+	if locked, ttl := LoginLocked(user.Username, ip); locked {
+		Redis.Del(totpTicketPrefix+ticket, totpTriesPrefix+ticket)
+		return model.ApiJson{State: false, Msg: map[string]interface{}{"msg": "account is locked", "locked": true, "retry": ttl}}
+	}
+	if !totpCheck(user, code) {
+		if LoginFail(user.Username, ip) == 0 {
+			Redis.Del(totpTicketPrefix+ticket, totpTriesPrefix+ticket)
+			return model.ApiJson{State: false, Msg: map[string]interface{}{"msg": "account is locked", "locked": true, "retry": Config.Login.Lockout}}
+		}
+		tries := loginIncr(totpTriesPrefix + ticket)
+		if tries >= totpMaxTries {
+			Redis.Del(totpTicketPrefix+ticket, totpTriesPrefix+ticket)
+			return model.ApiJson{State: false, Msg: "too many tries, please login again"}
+		}
+		return model.ApiJson{State: false, Msg: "code is error"}
+	}
+	Redis.Del(totpTicketPrefix+ticket, totpTriesPrefix+ticket)
+	LoginSuccess(user.Username)
+	return loginSession(user)
+}
+
+//////////私有方法
+/**
+ * 校验验证码或者恢复码，恢复码使用后失效
+ * @method totpCheck
+ */
+func totpCheck(user model.UserAdmin, code string) bool {
+	code = strings.TrimSpace(code)
+	usedKey := totpUsedPrefix + Tools.ParseString(user.ID)
+	if step, ok := Tools.TotpVerify(user.TotpSecret, code, 1); ok {
+		//比较和写入在一个脚本里完成，并发提交同一个验证码时只有一个请求成功
+		return Redis.SetIfGreater(usedKey, step, 120)
+	}
+	if user.TotpRecovery == "" || code == "" {
+		return false
+	}
+	hash := Tools.Sha256(strings.ToLower(code))
+	hashes := strings.Split(user.TotpRecovery, ",")
+	for i, h := range hashes {
+		if h == hash {
+			hashes = append(hashes[:i], hashes[i+1:]...)
+			//条件更新，同一个恢复码被并发使用时只有一个请求成功
+			return model.UserAdminUseTotpRecovery(user.ID, user.TotpRecovery, strings.Join(hashes, ","))
+		}
+	}
+	return false
+}
+
+/**
+ * 清除密钥和恢复码
+ * @method totpClear
+ */
+func totpClear(uid int) model.ApiJson {
+	if err := model.UserAdminSetTotp(uid, "", ""); err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	Redis.Del(totpPendingPrefix+Tools.ParseString(uid), totpUsedPrefix+Tools.ParseString(uid))
+	return model.ApiJson{State: true}
+}
+
+/**
+ * 生成恢复码，返回明文和sha256
+ * @method totpRecoveryCodes
+ */
+func totpRecoveryCodes() ([]string, []string) {
+	codes := make([]string, totpRecoveryCount)
+	hashes := make([]string, totpRecoveryCount)
+	for i := range codes {
+		codes[i] = Tools.RandomHex(5)
+		hashes[i] = Tools.Sha256(codes[i])
+	}
+	return codes, hashes
+}
@@ -0,0 +1,108 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+	"time"
+)
+
+const trashPurgeBatch = 100 //定时清理每次最多删除的文章数
+
+/**
+ * 回收站列表，有节点授权的用户只能看到授权节点下的文章
+ * @method ArticleTrashPage
+ * @param  {[type]} kw   string          [description]
+ * @param  {[type]} cp   int             [description]
+ * @param  {[type]} mp   int             [description]
+ * @param  {[type]} user model.UserAdmin 当前管理员
+ */
+func ArticleTrashPage(kw string, cp int, mp int, user model.UserAdmin) model.ApiJson {
+	paths, limited := NodeScope(user.ID)
+	return model.ArticleTrashPage(kw, cp, mp, paths, limited)
+}
+
+/**
+ * 从回收站恢复文章
+ * @method ArticleRestore
+ * @param  {[type]} ids  string          [description]
+ * @param  {[type]} user model.UserAdmin 操作人
+ */
+func ArticleRestore(ids string, user model.UserAdmin) model.ApiJson {
+	idsInt := Tools.ParseIds(ids)
+	if len(idsInt) == 0 {
+		return model.ApiJson{State: false, Msg: "id is error"}
+	}
+	if !ArticleAllowed(user.ID, idsInt) {
+		return model.ApiJson{State: false, Msg: "permission denied"}
+	}
+	restored, err := model.ArticleRestore(idsInt)
+	if err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	if len(restored) > 0 {
+		SearchSync(restored)
+		RankSync(restored)
+		CacheInvalidateArticles(restored)
+	}
+	return model.ApiJson{State: true, Msg: restored, Count: len(restored)}
+}
+
+/**
+ * 彻底删除回收站中的文章，文章的评论、历史版本、tag和旧slug一起删除
+ * @method ArticlePurge
+ * @param  {[type]} ids  string          [description]
+ * @param  {[type]} user model.UserAdmin 操作人
+ */
+func ArticlePurge(ids string, user model.UserAdmin) model.ApiJson {
+	idsInt := Tools.ParseIds(ids)
+	if len(idsInt) == 0 {
+		return model.ApiJson{State: false, Msg: "id is error"}
+	}
+	if !ArticleAllowed(user.ID, idsInt) {
+		return model.ApiJson{State: false, Msg: "permission denied"}
+	}
+	purged, err := articlePurge(idsInt)
+	if err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	return model.ApiJson{State: true, Msg: purged, Count: len(purged)}
+}
+
+/**
+ * 定时彻底删除在回收站中超过保留天数的文章
+ * @method ArticleTrashPurge
+ */
+func ArticleTrashPurge() {
+	days := Config.Trash.Retention
+	if days <= 0 {
+		return
+	}
+	ids := model.ArticleTrashDue(time.Now().AddDate(0, 0, -days).Unix(), trashPurgeBatch)
+	if len(ids) == 0 {
+		return
+	}
+	purged, err := articlePurge(ids)
+	if err != nil {
+		Tools.Logs("article purge error: " + err.Error())
+		return
+	}
+	if len(purged) > 0 {
+		AuditLog(model.UserAdmin{Username: "system"}, "", "article.purge", purged, nil, nil)
+	}
+}
+
+//////////私有方法
+func articlePurge(ids []int) ([]int, error) {
+	purged, err := model.ArticlePurge(ids)
+	if err != nil || len(purged) == 0 {
+		return purged, err
+	}
+	if err := model.ArticleTagDele(purged); err != nil {
+		Tools.Logs("article purge tag: " + err.Error())
+	}
+	if err := model.ArticleSlugDele(purged); err != nil {
+		Tools.Logs("article purge slug: " + err.Error())
+	}
+	SearchRemove(purged)
+	CacheInvalidateArticles(purged)
+	return purged, nil
+}
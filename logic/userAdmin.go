@@ -1,46 +1,147 @@
 package logic
 
 import (
-	"rmApi/model"
-	"strings"
+	"pizzaCmsApi/model"
 )
 
 /**
- * 判断用户是否登录
+ * 判断用户是否登录，失败次数过多会锁定用户名和ip
  * @method UserAdminLogin
- * @param  {[type]}  id       int    [description]
+ * @param  {[type]}  username string [description]
  * @param  {[type]}  password string [description]
+ * @param  {[type]}  ip       string [description]
  */
-func UserAdminCheckLogin(username string, password string) model.ApiJson {
+func UserAdminCheckLogin(username string, password string, ip string) model.ApiJson {
+	if locked, ttl := LoginLocked(username, ip); locked {
+		return model.ApiJson{State: false, Msg: map[string]interface{}{"msg": "account is locked", "locked": true, "retry": ttl}}
+	}
+	LoginDelay(username)
 	user := model.UserAdminCheckLogin(username)
-	if user.ID == 0 {
-		return model.ApiJson{State: false, Msg: "user is no exist"}
+	if user.ID == 0 || !checkPassword(user, password) {
+		remain := LoginFail(username, ip)
+		if remain == 0 {
+			return model.ApiJson{State: false, Msg: map[string]interface{}{"msg": "account is locked", "locked": true, "retry": Config.Login.Lockout}}
+		}
+		if user.ID == 0 {
+			return model.ApiJson{State: false, Msg: "user is no exist"}
+		}
+		return model.ApiJson{State: false, Msg: "username or password is error"}
+	}
+	if user.Totp == 1 { //开启了两步验证，需要再调用/useradmin/login/totp，验证通过后才清空失败次数
+		ticket, err := TotpTicket(user.ID)
+		if err != nil {
+			return model.ApiJson{State: false, Msg: err.Error()}
+		}
+		return model.ApiJson{State: true, Msg: map[string]interface{}{"totp": true, "ticket": ticket}}
+	}
+	LoginSuccess(username)
+	return loginSession(user)
+}
+
+/**
+ * 登录成功，创建会话
+ * @method loginSession
+ * @param  {[type]}  user model.UserAdmin [description]
+ */
+func loginSession(user model.UserAdmin) model.ApiJson {
+	user.Password = ""
+	user.Salt = ""
+	token, err := SessionCreate(user.ID)
+	if err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	return model.ApiJson{State: true, Msg: map[string]interface{}{"token": token, "expire": Config.Session.Expire, "user": user}}
+}
+
+/**
+ * 校验密码，旧的md5密码或者参数过时的hash在校验成功后自动升级
+ * @method checkPassword
+ * @param  {[type]}  user     model.UserAdmin [description]
+ * @param  {[type]}  password string          [description]
+ */
+func checkPassword(user model.UserAdmin, password string) bool {
+	hasher := Tools.Password()
+	if Tools.IsLegacyPassword(user.Password) {
+		if !Tools.VerifyLegacyPassword(user.Password, password, user.Salt) {
+			return false
+		}
 	} else {
-		pwd := Tools.MD5(password + user.Salt)
-		if pwd == user.Password {
-			user.Password = ""
-			return model.ApiJson{State: true, Msg: user}
+		if !hasher.Verify(user.Password, password) {
+			return false
+		}
+		if !hasher.NeedsRehash(user.Password) {
+			return true
+		}
+	}
+	if hash, err := hasher.Hash(password); err == nil {
+		if err := model.UserAdminSetPassword(user.ID, hash); err != nil {
+			Tools.Logs("rehash password error: " + err.Error())
 		} else {
-			return model.ApiJson{State: false, Msg: "username or password is error"}
+			SessionDestroyUser(user.ID) //密码hash变化，旧的会话一起失效
 		}
 	}
+	return true
 }
 
 /**
- * 删除用户
+ * 更新用户信息，修改了密码时注销该用户的全部会话，受保护的账号只能自己修改
+ * 修改其他用户时操作人必须拥有对方的全部权限，防止重置更高权限账号的密码后冒充登录
+ * @method UserAdminUpdate
+ * @param  {[type]} user     model.UserAdmin [description]
+ * @param  {[type]} operator int             操作人id
+ */
+func UserAdminUpdate(user model.UserAdmin, operator int) model.ApiJson {
+	if user.ID < 1 {
+		return model.ApiJson{State: false, Msg: "id is error"}
+	}
+	if msg := userAdminManageCheck(user.ID, operator); msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	result := model.UserAdminUpdate(user)
+	if result.State && user.Password != "" {
+		SessionDestroyUser(user.ID)
+	}
+	return result
+}
+
+/**
+ * 删除用户，不能删除受保护的账号和拥有操作人没有的权限的账号
  * @method UserAdminDele
- * @param  {[type]} ids string [description]
+ * @param  {[type]} ids      string [description]
+ * @param  {[type]} operator int    操作人id
  */
-func UserAdminDele(ids string) model.ApiJson {
-	idsArr := strings.Split(ids, ",")
-	length := len(idsArr)
-	if length > 0 {
-		var idsInt = make([]int, length, length)
-		for i, id := range idsArr {
-			idsInt[i] = Tools.ParseInt(id, 0)
-		}
-		return model.UserAdminDele(idsInt)
-	} else {
+func UserAdminDele(ids string, operator int) model.ApiJson {
+	idsInt := Tools.ParseIds(ids)
+	if len(idsInt) == 0 {
 		return model.ApiJson{State: false, Msg: "id is error"}
 	}
+	for _, id := range idsInt {
+		if IsProtectedUserAdmin(id) {
+			return model.ApiJson{State: false, Msg: "protected user can not be deleted"}
+		}
+		if msg := roleGrantCheck(operator, model.UserRoleGet(id)); msg != "" {
+			return model.ApiJson{State: false, Msg: msg}
+		}
+	}
+	result := model.UserAdminDele(idsInt)
+	if result.State {
+		model.UserRoleDele(idsInt)
+		model.UserNodeDele(idsInt)
+		for _, id := range idsInt {
+			SessionDestroyUser(id)
+		}
+	}
+	return result
+}
+
+//////////私有方法
+//操作人能否修改这个用户：受保护的账号只能自己修改，修改其他人时操作人必须拥有对方的全部权限
+func userAdminManageCheck(uid int, operator int) string {
+	if uid == operator {
+		return ""
+	}
+	if IsProtectedUserAdmin(uid) {
+		return "protected user can only be changed by itself"
+	}
+	return roleGrantCheck(operator, model.UserRoleGet(uid))
 }
@@ -0,0 +1,24 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+	"testing"
+)
+
+//没有id时必须在访问数据库之前拒绝，否则gorm不加where条件会更新所有用户
+func TestUserAdminUpdateRequiresID(t *testing.T) {
+	for _, id := range []int{0, -1} {
+		user := model.UserAdmin{ID: id, Username: "someone", Password: "secret1"}
+		if result := UserAdminUpdate(user, 1); result.State {
+			t.Errorf("UserAdminUpdate() with id %d succeeded", id)
+		}
+	}
+}
+
+func TestUserAdminDeleRequiresID(t *testing.T) {
+	for _, ids := range []string{"", "0", "a,b"} {
+		if result := UserAdminDele(ids, 1); result.State {
+			t.Errorf("UserAdminDele(%q) succeeded", ids)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+	"strings"
+)
+
+/**
+ * 用户可以管理的节点范围，返回授权节点的路径，limited为false时不限节点
+ * 超级管理员和标记为不限节点的用户不限节点，其他用户只能管理授权的节点，没有授权时不能管理任何节点
+ * @method NodeScope
+ * @param  {[type]} uid int [description]
+ */
+func NodeScope(uid int) (paths []string, limited bool) {
+	if HasPermission(uid, PermAll) || model.UserNodeAll(uid) {
+		return nil, false
+	}
+	paths = []string{}
+	for _, node := range model.UserNodeGet(uid) {
+		if node.Nodepath != "" {
+			paths = append(paths, node.Nodepath)
+		}
+	}
+	return paths, true
+}
+
+/**
+ * 用户是否可以管理某个节点下的文章，授权会继承到所有下级节点
+ * @method NodeAllowed
+ * @param  {[type]} uid    int [description]
+ * @param  {[type]} nodeid int [description]
+ */
+func NodeAllowed(uid int, nodeid int) bool {
+	paths, limited := NodeScope(uid)
+	if !limited {
+		return true
+	}
+	return nodeInScope(model.NodeFind(nodeid).Nodepath, paths)
+}
+
+/**
+ * 用户是否可以管理所有这些文章，文章或者节点不存在时不允许
+ * @method ArticleAllowed
+ * @param  {[type]} uid int   [description]
+ * @param  {[type]} ids []int [description]
+ */
+func ArticleAllowed(uid int, ids []int) bool {
+	paths, limited := NodeScope(uid)
+	if !limited || len(ids) == 0 {
+		return true
+	}
+	nodepaths := model.ArticleNodePaths(ids)
+	for _, id := range ids {
+		if nodepath, ok := nodepaths[id]; !ok || !nodeInScope(nodepath, paths) {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * 后台文章列表，有节点授权的用户只能看到授权节点下的文章
+ * @method ArticlePageScoped
+ * @param  {[type]} kw     string [description]
+ * @param  {[type]} nodeid int    [description]
+ * @param  {[type]} cp     int    [description]
+ * @param  {[type]} mp     int    [description]
+ * @param  {[type]} uid    int    当前用户id
+ */
+func ArticlePageScoped(kw string, nodeid int, cp int, mp int, uid int) model.ApiJson {
+	paths, limited := NodeScope(uid)
+	if !limited {
+		return ArticlePage(kw, nodeid, cp, mp, false)
+	}
+	return model.ArticlePageScope(kw, nodeid, cp, mp, paths, model.NodeSorts[NodeSettingGet(nodeid).Sort])
+}
+
+/**
+ * 设置用户的节点授权，all为true时不限节点，否则只能管理授权的节点，授权为空时不能管理任何节点
+ * 有节点限制的操作人只能在自己的授权范围内分配，不能授予不限节点，也不能修改授权超出自己范围的用户
+ * @method UserNodeSet
+ * @param  {[type]} uid      int    [description]
+ * @param  {[type]} nodeids  string 节点id，逗号隔开
+ * @param  {[type]} all      bool   是否不限节点
+ * @param  {[type]} operator int    操作人id
+ */
+func UserNodeSet(uid int, nodeids string, all bool, operator int) model.ApiJson {
+	if IsProtectedUserAdmin(uid) {
+		return model.ApiJson{State: false, Msg: "protected user's node can not be changed"}
+	}
+	ids := Tools.ParseIds(nodeids)
+	var nodepaths []string
+	for _, id := range ids {
+		node := model.NodeFind(id)
+		if node.ID == 0 {
+			return model.ApiJson{State: false, Msg: "node " + Tools.ParseString(id) + " is no exist"}
+		}
+		nodepaths = append(nodepaths, node.Nodepath)
+	}
+	//新旧授权都必须在操作人的范围内
+	for _, node := range model.UserNodeGet(uid) {
+		nodepaths = append(nodepaths, node.Nodepath)
+	}
+	paths, limited := NodeScope(operator)
+	if msg := nodeGrantCheck(paths, limited, all || model.UserNodeAll(uid), nodepaths); msg != "" {
+		return model.ApiJson{State: false, Msg: msg}
+	}
+	return model.UserNodeSet(uid, ids, all)
+}
+
+/**
+ * 后台获取文章，不在授权节点下的文章只能获取前台可见的内容
+ * @method ArticleGet
+ * @param  {[type]} id   int             [description]
+ * @param  {[type]} user model.UserAdmin 当前管理员
+ */
+func ArticleGet(id int, user model.UserAdmin) model.ApiJson {
+	if !ArticleAllowed(user.ID, []int{id}) {
+		return ArticleGetPublic(id)
+	}
+	return model.ArticleGet(id)
+}
+
+/**
+ * 待审核列表，只包含授权节点下的文章
+ * @method ArticleReviewQueue
+ * @param  {[type]} user model.UserAdmin 审核人
+ * @param  {[type]} all  bool            是否包含未指定审核人的文章
+ * @param  {[type]} cp   int             [description]
+ * @param  {[type]} mp   int             [description]
+ */
+func ArticleReviewQueue(user model.UserAdmin, all bool, cp int, mp int) model.ApiJson {
+	paths, limited := NodeScope(user.ID)
+	return model.ArticleReviewQueue(user.ID, all, cp, mp, paths, limited)
+}
+
+//////////私有方法
+//节点路径是否在授权的某个节点路径下(包括自身)
+func nodeInScope(nodepath string, paths []string) bool {
+	if nodepath == "" {
+		return false
+	}
+	for _, path := range paths {
+		if path != "" && strings.HasPrefix(nodepath, path) {
+			return true
+		}
+	}
+	return false
+}
+
+//操作人的节点范围是否覆盖要分配的授权，paths和limited为操作人的NodeScope，all为是否涉及不限节点
+func nodeGrantCheck(paths []string, limited bool, all bool, nodepaths []string) string {
+	if !limited {
+		return ""
+	}
+	if all {
+		return "permission denied: can not grant all nodes"
+	}
+	for _, nodepath := range nodepaths {
+		if !nodeInScope(nodepath, paths) {
+			return "permission denied: node is out of your scope"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package logic
+
+import "testing"
+
+func TestNodeInScope(t *testing.T) {
+	paths := []string{",1,3,", ",5,"}
+	tests := []struct {
+		nodepath string
+		paths    []string
+		want     bool
+	}{
+		{",1,3,", paths, true},
+		{",1,3,9,", paths, true},
+		{",5,7,", paths, true},
+		{",1,", paths, false},
+		{",1,30,", paths, false},
+		{",50,", paths, false},
+		{"", paths, false},
+		{",1,3,", nil, false},
+		{",1,3,", []string{""}, false},
+	}
+	for _, tt := range tests {
+		if got := nodeInScope(tt.nodepath, tt.paths); got != tt.want {
+			t.Errorf("nodeInScope(%q, %q) = %v, want %v", tt.nodepath, tt.paths, got, tt.want)
+		}
+	}
+}
+
+//有节点限制的管理员不能给自己或他人授予不限节点或者范围外的节点
+func TestNodeGrantCheck(t *testing.T) {
+	scope := []string{",1,3,"}
+	tests := []struct {
+		name      string
+		paths     []string
+		limited   bool
+		all       bool
+		nodepaths []string
+		ok        bool
+	}{
+		{"unlimited grants all", nil, false, true, nil, true},
+		{"unlimited grants any node", nil, false, false, []string{",5,"}, true},
+		{"limited grants all", scope, true, true, nil, false},
+		{"limited grants own node", scope, true, false, []string{",1,3,"}, true},
+		{"limited grants child node", scope, true, false, []string{",1,3,9,"}, true},
+		{"limited grants parent node", scope, true, false, []string{",1,"}, false},
+		{"limited grants other node", scope, true, false, []string{",1,3,9,", ",5,"}, false},
+		{"limited without nodes grants node", []string{}, true, false, []string{",1,3,"}, false},
+		{"limited clears grants", scope, true, false, nil, true},
+	}
+	for _, tt := range tests {
+		if got := nodeGrantCheck(tt.paths, tt.limited, tt.all, tt.nodepaths); (got == "") != tt.ok {
+			t.Errorf("%s: nodeGrantCheck() = %q, want ok=%v", tt.name, got, tt.ok)
+		}
+	}
+}
@@ -3,7 +3,7 @@ package logic
 import (
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
-	"rmApi/model"
+	"pizzaCmsApi/model"
 )
 
 /**
@@ -0,0 +1,116 @@
+package logic
+
+import (
+	redigo "github.com/garyburd/redigo/redis"
+	"pizzaCmsApi/model"
+	"time"
+)
+
+const (
+	viewSeenPrefix = "view:seen:"    //访客已访问过的文章，view:seen:文章id:访客
+	viewPendingKey = "view:pending"  //还没有写入数据库的浏览数，hash 文章id=>增量
+	viewFlushKey   = "view:flushing" //正在写入数据库的浏览数
+	viewBatchField = "batch"         //正在写入的hash中保存批次的字段，数据库按批次记录已写入的文章
+	viewFlushKeep  = 86400           //数据库中写入记录的保留时间，单位秒
+	viewFlushBatch = 500             //每条update语句更新的文章数
+)
+
+/**
+ * 记录一次浏览，同一访客在窗口时间内重复访问不计数，返回当前浏览数
+ * 浏览数先累加在redis中，由定时任务批量写入数据库
+ * @method ArticleView
+ * @param  {[type]} id      int    [description]
+ * @param  {[type]} visitor string 访客标识，如ip和user agent
+ */
+func ArticleView(id int, visitor string) model.ApiJson {
+	if !ArticleGetPublic(id).State { //使用文章缓存，不查询数据库
+		return model.ApiJson{State: false, Msg: "article is no exist"}
+	}
+	field := Tools.ParseString(id)
+	key := viewSeenPrefix + field + ":" + Tools.Sha256(visitor)[:16]
+	first := true
+	if Config.View.Window > 0 {
+		_, err := redigo.String(Redis.Do("SET", key, 1, "NX", "EX", Config.View.Window))
+		first = err == nil
+	}
+	if first {
+		Redis.Do("HINCRBY", viewPendingKey, field, 1)
+	}
+	return model.ApiJson{State: true, Msg: ArticleViewCount(id)}
+}
+
+/**
+ * 文章的浏览数，包含还没有写入数据库的部分
+ * @method ArticleViewCount
+ * @param  {[type]} id int [description]
+ */
+func ArticleViewCount(id int) int {
+	field := Tools.ParseString(id)
+	pending, _ := redigo.Int(Redis.Do("HGET", viewPendingKey, field))
+	flushing, _ := redigo.Int(Redis.Do("HGET", viewFlushKey, field))
+	return model.ArticleCountGet(id) + pending + flushing
+}
+
+/**
+ * 把redis中累加的浏览数写入数据库，由定时任务调用
+ * 先把待写入的hash改名，之后的浏览计入新的hash，改名后的hash中记录一个批次号
+ * 数据库在同一个事务中记录这个批次已经写入的文章，每批写入后再从hash中删除
+ * 写入失败或中断时剩下的部分下次用同一个批次号再写，已经写入的文章会跳过，不会重复计数
+ * @method ArticleViewFlush
+ */
+func ArticleViewFlush() {
+	//上次写入中断时先处理遗留的数据
+	if exists, _ := redigo.Bool(Redis.Do("EXISTS", viewFlushKey)); !exists {
+		if _, err := Redis.Do("RENAME", viewPendingKey, viewFlushKey); err != nil {
+			return //没有新的浏览
+		}
+	}
+	//改名后中断时还没有批次号，这时还没有写入过，重新生成即可
+	Redis.Do("HSETNX", viewFlushKey, viewBatchField, time.Now().UnixNano())
+	values, err := redigo.Int64Map(Redis.Do("HGETALL", viewFlushKey))
+	if err != nil {
+		Tools.Logs("view flush error: " + err.Error())
+		return
+	}
+	batch := values[viewBatchField]
+	deltas := map[int]int{}
+	failed := false
+	for field, delta := range values {
+		if id := Tools.ParseInt(field, 0); id > 0 && delta > 0 {
+			deltas[id] = int(delta)
+			if len(deltas) >= viewFlushBatch {
+				failed = !viewSave(deltas, batch) || failed
+				deltas = map[int]int{}
+			}
+		}
+	}
+	failed = !viewSave(deltas, batch) || failed
+	if failed {
+		return
+	}
+	//全部写入后删除批次，下次改名时使用新的批次号
+	Redis.Del(viewFlushKey)
+	if err := model.ArticleViewFlushPurge(time.Now().Add(-viewFlushKeep * time.Second).UnixNano()); err != nil {
+		Tools.Logs("view flush purge error: " + err.Error())
+	}
+}
+
+//////////私有方法
+//写入数据库并更新排行榜，成功后从正在写入的hash中删除这一批，失败时保留，下次再写
+func viewSave(deltas map[int]int, batch int64) bool {
+	if len(deltas) == 0 {
+		return true
+	}
+	added, err := model.ArticleCountAdd(deltas, Tools.ParseInt(time.Now().Format("20060102"), 0), batch)
+	if err != nil {
+		Tools.Logs("view flush error: " + err.Error())
+		return false
+	}
+	fields := []interface{}{viewFlushKey}
+	for id := range deltas {
+		fields = append(fields, Tools.ParseString(id))
+	}
+	Redis.Do("HDEL", fields...)
+	RankViews(added)
+	return true
+}
@@ -0,0 +1,118 @@
+package logic
+
+import (
+	"pizzaCmsApi/model"
+)
+
+//允许的状态流转，以及每个流转需要的权限
+var articleTransitions = map[string]map[string]string{
+	model.ArticleDraft: {
+		model.ArticleReview: PermArticleEdit,
+	},
+	model.ArticleReview: {
+		model.ArticleDraft:    PermArticleEdit, //撤回
+		model.ArticleApproved: PermArticlePass,
+		model.ArticleRejected: PermArticlePass,
+	},
+	model.ArticleRejected: {
+		model.ArticleDraft:  PermArticleEdit,
+		model.ArticleReview: PermArticleEdit,
+	},
+	model.ArticleApproved: {
+		model.ArticleDraft:     PermArticleEdit,
+		model.ArticlePublished: PermArticlePass,
+	},
+	model.ArticlePublished: {
+		model.ArticleDraft:    PermArticlePass,
+		model.ArticleArchived: PermArticlePass,
+	},
+	model.ArticleArchived: {
+		model.ArticleDraft:     PermArticleEdit,
+		model.ArticlePublished: PermArticlePass,
+	},
+}
+
+/**
+ * 修改文章的编辑流程状态
+ * @method ArticleTransition
+ * @param  {[type]} id       int             文章id
+ * @param  {[type]} to       string          目标状态
+ * @param  {[type]} note     string          审核意见，审核不通过时必填
+ * @param  {[type]} reviewer int             提交审核时指定的审核人，0表示不指定
+ * @param  {[type]} user     model.UserAdmin 操作人
+ */
+func ArticleTransition(id int, to string, note string, reviewer int, user model.UserAdmin) model.ApiJson {
+	articles := model.ArticleList([]int{id})
+	if len(articles) == 0 {
+		return model.ApiJson{State: false, Msg: "article is no exist"}
+	}
+	if articles[0].TrashAt > 0 {
+		return model.ApiJson{State: false, Msg: "article is in trash"}
+	}
+	from := articles[0].Status
+	if from == "" {
+		from = model.ArticleDraft
+	}
+	perm, ok := articleTransitions[from][to]
+	if !ok {
+		return model.ApiJson{State: false, Msg: "can not change status from " + from + " to " + to}
+	}
+	if !HasPermission(user.ID, perm) || !NodeAllowed(user.ID, articles[0].Nodeid) {
+		return model.ApiJson{State: false, Msg: "permission denied"}
+	}
+	fields := map[string]interface{}{}
+	switch to {
+	case model.ArticleRejected:
+		if note == "" {
+			return model.ApiJson{State: false, Msg: "review note is required"}
+		}
+		fields["review_note"] = note
+	case model.ArticleApproved, model.ArticlePublished:
+		fields["review_note"] = note
+		if from == model.ArticleReview {
+			fields["reviewer"] = user.ID
+		}
+	case model.ArticleReview:
+		fields["review_note"] = ""
+		if reviewer > 0 {
+			if !HasPermission(reviewer, PermArticlePass) || !NodeAllowed(reviewer, articles[0].Nodeid) {
+				return model.ApiJson{State: false, Msg: "reviewer has no permission"}
+			}
+			fields["reviewer"] = reviewer
+		}
+	}
+	if err := model.ArticleSetStatus(id, articles[0].Status, to, fields); err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	SearchSync([]int{id})
+	CacheInvalidateArticles([]int{id})
+	return model.ApiJson{State: true}
+}
+
+/**
+ * 指定审核人，只有待审核的文章可以指定，操作人和审核人都需要有文章所在节点的权限
+ * @method ArticleAssignReviewer
+ * @param  {[type]} id       int             [description]
+ * @param  {[type]} reviewer int             [description]
+ * @param  {[type]} user     model.UserAdmin 操作人
+ */
+func ArticleAssignReviewer(id int, reviewer int, user model.UserAdmin) model.ApiJson {
+	articles := model.ArticleList([]int{id})
+	if len(articles) == 0 {
+		return model.ApiJson{State: false, Msg: "article is no exist"}
+	}
+	if articles[0].TrashAt > 0 || articles[0].Status != model.ArticleReview {
+		return model.ApiJson{State: false, Msg: "article is not in review"}
+	}
+	if !NodeAllowed(user.ID, articles[0].Nodeid) {
+		return model.ApiJson{State: false, Msg: "permission denied"}
+	}
+	if !HasPermission(reviewer, PermArticlePass) || !NodeAllowed(reviewer, articles[0].Nodeid) {
+		return model.ApiJson{State: false, Msg: "reviewer has no permission"}
+	}
+	if err := model.ArticleSetReviewer(id, reviewer); err != nil {
+		return model.ApiJson{State: false, Msg: err.Error()}
+	}
+	CacheInvalidateArticles([]int{id})
+	return model.ApiJson{State: true}
+}
@@ -1,12 +1,24 @@
 package main
 
 import (
+	"flag"
 	"github.com/iris-contrib/middleware/logger"
 	"github.com/kataras/iris"
-	"rmApi/controller"
+	"log"
+	"pizzaCmsApi/controller"
+	"pizzaCmsApi/logic"
 )
 
 func main() {
+	cmd := flag.String("cmd", "", "运行命令行任务后退出，如reindex")
+	flag.Parse()
+	if *cmd != "" {
+		if err := logic.Command(*cmd); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	api := iris.New()
 	api.Use(logger.New())
 	errorLogger := logger.New()
@@ -24,24 +36,98 @@ func main() {
 	//useradmin
 	api.Get("/useradmin", controller.UserAdminGet)           // /useradmin?id=1
 	api.Get("/useradmin/:id", controller.UserAdminGetByPath) //useradmin/1
-	api.Put("/useradmin", controller.UserAdminUpdate)
-	api.Post("/useradmin", controller.UserAdminCreate)
-	api.Post("/useradmin/page", controller.UserAdminPage)
+	api.Put("/useradmin", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.UserAdminUpdate)
+	api.Post("/useradmin", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.UserAdminCreate)
+	api.Post("/useradmin/page", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.UserAdminPage)
 	api.Post("/useradmin/login", controller.UserAdminCheckLogin)
-	api.Delete("/useradmin", controller.UserAdminDele)
+	api.Post("/useradmin/login/totp", controller.TotpLogin)
+	api.Post("/useradmin/logout", controller.AuthAdmin, controller.UserAdminLogout)
+	api.Post("/useradmin/refresh", controller.AuthAdmin, controller.UserAdminRefresh)
+	api.Post("/useradmin/unlock", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.UserAdminUnlock)
+	api.Delete("/useradmin", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.UserAdminDele)
+	api.Post("/useradmin/totp/enroll", controller.AuthAdmin, controller.TotpEnroll)
+	api.Post("/useradmin/totp/enable", controller.AuthAdmin, controller.TotpEnable)
+	api.Post("/useradmin/totp/disable", controller.AuthAdmin, controller.TotpDisable)
+	api.Post("/useradmin/totp/reset", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.TotpReset)
+	//role
+	api.Post("/useradmin/role/page", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.RolePage)
+	api.Post("/useradmin/role/permission", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.RolePermission)
+	api.Post("/useradmin/role", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.RoleCreate)
+	api.Put("/useradmin/role", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.RoleUpdate)
+	api.Delete("/useradmin/role", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.RoleDele)
+	api.Post("/useradmin/role/user", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.UserRoleGet)
+	api.Post("/useradmin/role/assign", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.UserRoleAssign)
+	api.Post("/useradmin/node", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.UserNodeGet)
+	api.Post("/useradmin/node/assign", controller.AuthAdmin, controller.Permission(logic.PermUserAdmin), controller.UserNodeAssign)
 	//article
 	api.Get("/article/:id", controller.ArticleGet) //user/1
-	api.Put("/article", controller.ArticleUpdate)
-	api.Post("/article", controller.ArticleCreate)
-	api.Post("/article/page", controller.ArticlePage)
-	api.Post("/article/pass", controller.ArticlePass)
-	api.Delete("/article", controller.ArticleDele)
+	api.Get("/slug/:slug", controller.ArticleGetBySlug)
+	api.Put("/article", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.ArticleUpdate)
+	api.Post("/article", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.ArticleCreate)
+	api.Post("/article/list", controller.ArticleList)
+	api.Post("/article/view", controller.ArticleView)
+	api.Post("/article/rank", controller.ArticleRank)
+	api.Post("/article/preview", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.ArticlePreview)
+	api.Post("/article/page", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.ArticlePage)
+	api.Post("/article/pass", controller.AuthAdmin, controller.Permission(logic.PermArticlePass), controller.ArticlePass)
+	api.Post("/article/workflow", controller.AuthAdmin, controller.ArticleWorkflow)
+	api.Post("/article/workflow/assign", controller.AuthAdmin, controller.Permission(logic.PermArticlePass), controller.ArticleAssignReviewer)
+	api.Post("/article/workflow/queue", controller.AuthAdmin, controller.Permission(logic.PermArticlePass), controller.ArticleReviewQueue)
+	api.Delete("/article", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.ArticleDele)
+	api.Post("/article/trash/page", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.ArticleTrashPage)
+	api.Post("/article/trash/restore", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.ArticleRestore)
+	api.Post("/article/trash/purge", controller.AuthAdmin, controller.Permission(logic.PermArticlePurge), controller.ArticlePurge)
+	api.Post("/article/revision/page", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.RevisionPage)
+	api.Post("/article/revision/get", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.RevisionGet)
+	api.Post("/article/revision/diff", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.RevisionDiff)
+	api.Post("/article/revision/restore", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.RevisionRestore)
+	//tag
+	api.Get("/tag/:name/articles", controller.TagArticles)
+	api.Post("/tag/cloud", controller.TagCloud)
+	api.Post("/tag/page", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.TagPage)
+	api.Post("/tag", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.TagCreate)
+	api.Put("/tag", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.TagUpdate)
+	api.Delete("/tag", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.TagDele)
+	api.Post("/tag/merge", controller.AuthAdmin, controller.Permission(logic.PermArticleEdit), controller.TagMerge)
+	//search
+	api.Post("/search", controller.Search)
+	api.Post("/search/suggest", controller.SearchSuggest)
+	api.Post("/search/hot", controller.SearchHot)
+	//cache
+	api.Post("/cache/stats", controller.AuthAdmin, controller.Permission(logic.PermCache), controller.CacheStats)
+	api.Post("/cache/clear", controller.AuthAdmin, controller.Permission(logic.PermCache), controller.CacheClear)
+	//audit
+	api.Post("/audit/page", controller.AuthAdmin, controller.Permission(logic.PermAuditView), controller.AuditPage)
 	//node
-	// api.Get("/node/pageall", controller.NodePageAll)
-	// api.Get("/node/:id", controller.NodeGet) //user/1
-	// api.Put("/node", controller.NodeUpdate)
-	// api.Post("/node", controller.NodeCreate)
-	// api.Post("/node/page", controller.NodePage)
+	api.Get("/node/:id", controller.NodeGet) //node/1
+	api.Post("/node/tree", controller.NodeTree)
+	api.Post("/node/tree/all", controller.AuthAdmin, controller.NodeTreeAll)
+	api.Post("/node/setting", controller.NodeSetting)
+	api.Put("/node", controller.AuthAdmin, controller.Permission(logic.PermNode), controller.NodeUpdate)
+	api.Post("/node", controller.AuthAdmin, controller.Permission(logic.PermNode), controller.NodeCreate)
+	api.Delete("/node", controller.AuthAdmin, controller.Permission(logic.PermNode), controller.NodeDele)
+	api.Post("/node/move", controller.AuthAdmin, controller.Permission(logic.PermNode), controller.NodeMove)
+	api.Post("/node/merge", controller.AuthAdmin, controller.Permission(logic.PermNode), controller.NodeMerge)
+	api.Get("/node/:id/breadcrumb", controller.NodeBreadcrumb)
+	//comment
+	api.Get("/comment/:id", controller.CommentGet)
+	api.Post("/comment", controller.CommentCreate)
+	api.Put("/comment", controller.AuthAdmin, controller.Permission(logic.PermComment), controller.CommentUpdate)
+	api.Delete("/comment", controller.AuthAdmin, controller.Permission(logic.PermComment), controller.CommentDele)
+	api.Post("/comment/page", controller.AuthAdmin, controller.Permission(logic.PermComment), controller.CommentPage)
+	api.Post("/comment/pass", controller.AuthAdmin, controller.Permission(logic.PermComment), controller.CommentPass)
+	//menu
+	api.Post("/menu/get", controller.MenuGet)
+	api.Post("/menu/page", controller.AuthAdmin, controller.Permission(logic.PermMenu), controller.MenuPage)
+	api.Post("/menu", controller.AuthAdmin, controller.Permission(logic.PermMenu), controller.MenuCreate)
+	api.Put("/menu", controller.AuthAdmin, controller.Permission(logic.PermMenu), controller.MenuUpdate)
+	api.Delete("/menu", controller.AuthAdmin, controller.Permission(logic.PermMenu), controller.MenuDele)
+	api.Post("/menu/items", controller.AuthAdmin, controller.Permission(logic.PermMenu), controller.MenuItems)
+	api.Post("/menu/item", controller.AuthAdmin, controller.Permission(logic.PermMenu), controller.MenuItemCreate)
+	api.Put("/menu/item", controller.AuthAdmin, controller.Permission(logic.PermMenu), controller.MenuItemUpdate)
+	api.Delete("/menu/item", controller.AuthAdmin, controller.Permission(logic.PermMenu), controller.MenuItemDele)
+	api.Post("/menu/item/sort", controller.AuthAdmin, controller.Permission(logic.PermMenu), controller.MenuItemSort)
 
+	logic.CronStart()
 	api.Listen("0.0.0.0:8081")
 }
@@ -1,6 +1,27 @@
 package model
 
-import ()
+import (
+	"errors"
+	"github.com/jinzhu/gorm"
+	"strings"
+	"time"
+)
+
+//文章的编辑流程状态
+const (
+	ArticleDraft     = "draft"     //草稿
+	ArticleReview    = "review"    //待审核
+	ArticleApproved  = "approved"  //审核通过，等待发布
+	ArticlePublished = "published" //已发布，pass=1
+	ArticleArchived  = "archived"  //已归档(下线)
+	ArticleRejected  = "rejected"  //审核不通过
+)
+
+//文章内容的格式
+const (
+	ArticleHtml     = "html"     //直接编辑html
+	ArticleMarkdown = "markdown" //编辑markdown，保存时渲染成html
+)
 
 type Article struct {
 	ID         int    `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
@@ -19,6 +40,18 @@ type Article struct {
 	Link       string `json:"link" sql:"type:varchar(100);default:''"`
 	Comment    int    `json:"comment" sql:"default:0"`
 	State      int    `json:"state" sql:"default:0"`
+	PublishAt  int64  `json:"publish_at" sql:"default:0"`                     //定时发布时间，发布后清零
+	ExpireAt   int64  `json:"expire_at" sql:"default:0"`                      //自动下线时间，下线后清零
+	Status     string `json:"status" sql:"type:varchar(20);default:'draft'"`  //编辑流程状态，只能通过流程接口修改
+	Reviewer   int    `json:"reviewer" sql:"default:0"`                       //审核人id
+	ReviewNote string `json:"review_note" sql:"type:varchar(255);default:''"` //审核意见
+	Slug       string `json:"slug" sql:"type:varchar(100);default:''"`        //固定链接，唯一
+	Format     string `json:"format" sql:"type:varchar(10);default:'html'"`   //内容格式，html或markdown
+	Markdown   string `json:"markdown" sql:"type:text"`                       //markdown原文，content为渲染并过滤后的html
+	Words      int    `json:"words" sql:"default:0"`                          //字数，中文按字、英文按单词计算
+	Readtime   int    `json:"readtime" sql:"default:0"`                       //预计阅读时间，单位分钟
+	TrashAt    int64  `json:"trash_at" sql:"default:0"`                       //移到回收站的时间，0表示不在回收站
+	TrashBy    int    `json:"trash_by" sql:"default:0"`                       //删除人id
 }
 
 type ArticleResults struct {
@@ -32,6 +65,30 @@ func (u Article) TableName() string {
 	return "pz_article"
 }
 
+/**
+ * 去掉只在后台使用的字段(审核人、审核意见、markdown原文、删除人)，用于前台输出
+ * @method Public
+ */
+func (u Article) Public() Article {
+	u.Reviewer = 0
+	u.ReviewNote = ""
+	u.Markdown = ""
+	u.TrashBy = 0
+	return u
+}
+
+/**
+ * 文章列表用于前台输出，见Article.Public
+ * @method ArticlesPublic
+ * @param  {[type]} articles []ArticleResults [description]
+ */
+func ArticlesPublic(articles []ArticleResults) []ArticleResults {
+	for i := range articles {
+		articles[i].Article = articles[i].Article.Public()
+	}
+	return articles
+}
+
 /**
  * 根据article id获取 article
  * @method ArticleGet
@@ -43,13 +100,27 @@ func ArticleGet(id int) ApiJson {
 	return ApiJson{State: true, Msg: article}
 }
 
+/**
+ * 根据id数组获取article，用于审计日志等内部场景
+ * @method ArticleList
+ * @param  {[type]} ids []int [description]
+ */
+func ArticleList(ids []int) []Article {
+	var articles []Article
+	DB.Where("id in (?) ", ids).Find(&articles)
+	return articles
+}
+
 /**
  * 更新article信息
  * @method ArticleUpdate
  * @param  {[type]}   article Article [description]
  */
 func ArticleUpdate(article Article) ApiJson {
-	err := DB.Model(&article).UpdateColumns(map[string]interface{}{"title": article.Title, "timg": article.Timg, "content": article.Content, "brief": article.Brief, "nodeid": article.Nodeid, "reco": article.Reco, "pass": article.Pass, "source": article.Source, "tags": article.Tags, "Link": article.Link}).Error
+	if article.ID < 1 { //没有主键时gorm不加where条件，会更新所有文章
+		return ApiJson{State: false, Msg: "id is error"}
+	}
+	err := DB.Model(Article{}).Where("id = ?", article.ID).UpdateColumns(map[string]interface{}{"title": article.Title, "timg": article.Timg, "content": article.Content, "brief": article.Brief, "nodeid": article.Nodeid, "reco": article.Reco, "source": article.Source, "tags": article.Tags, "Link": article.Link, "publish_at": article.PublishAt, "expire_at": article.ExpireAt, "format": article.Format, "markdown": article.Markdown, "words": article.Words, "readtime": article.Readtime}).Error
 	if err != nil {
 		return ApiJson{State: false, Msg: err}
 	}
@@ -57,56 +128,327 @@ func ArticleUpdate(article Article) ApiJson {
 }
 
 /**
- * 创建article
+ * 创建article，新文章都是草稿
  * @method ArticleCreate
  * @param  {[type]}   article Article [description]
  */
 func ArticleCreate(article Article) ApiJson {
-	DB.Save(&article)
+	article.Status = ArticleDraft
+	article.Pass = 0
+	article.Reviewer = 0
+	article.ReviewNote = ""
+	if err := DB.Save(&article).Error; err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
 	return ApiJson{State: true, Msg: article.ID}
 }
 
 /**
  * 获取所有的article、
  * @method ArticlePage
- * @param  {[type]} kw string [description]
- * @param  {[type]} cp int    [description]
- * @param  {[type]} mp int    [description]
+ * @param  {[type]} kw     string [description]
+ * @param  {[type]} cp     int    [description]
+ * @param  {[type]} mp     int    [description]
+ * @param  {[type]} public bool   前台读取，只返回已发布且在发布时间内的文章
+ * @param  {[type]} order  string 排序，见NodeSorts，为空时按id降序
  */
-func ArticlePage(kw string, nodeid int, cp int, mp int) ApiJson {
-	var articles []ArticleResults
-	var count int
-	// var param []interface{}
-	// param=append("%"+kw+"%", nodeid, mp, (cp-1)*mp)
-	DB.Raw("select a.*,b.`name` as nodename,c.username from pz_article as a,pz_node as b,pz_user as c where a.nodeid = b.id and a.uid = c.id and a.title like ? and b.nodepath like ?  limit ? offset ?", "%"+kw+"%", "%,"+Tools.ParseString(nodeid)+",%", mp, (cp-1)*mp).Scan(&articles)
+func ArticlePage(kw string, nodeid int, cp int, mp int, public bool, order string) ApiJson {
+	where := "a.nodeid = b.id and a.uid = c.id and a.trash_at = 0 and a.title like ? and b.nodepath like ?"
+	param := []interface{}{"%" + kw + "%", "%," + Tools.ParseString(nodeid) + ",%"}
+	if public {
+		now := time.Now().Unix()
+		where += " and " + ArticlePublicWhere("a.")
+		param = append(param, now, now)
+	}
+	result := articlePage(where, param, cp, mp, order)
+	if public {
+		result.Msg = ArticlesPublic(result.Msg.([]ArticleResults))
+	}
+	return result
+}
 
-	return ApiJson{State: true, Msg: articles, Count: count}
+/**
+ * 后台分页获取某些节点路径下的文章，用于有节点授权的用户，paths为空时没有结果
+ * @method ArticlePageScope
+ * @param  {[type]} kw     string   [description]
+ * @param  {[type]} nodeid int      [description]
+ * @param  {[type]} cp     int      [description]
+ * @param  {[type]} mp     int      [description]
+ * @param  {[type]} paths  []string 授权节点的路径
+ * @param  {[type]} order  string   排序，见NodeSorts，为空时按id降序
+ */
+func ArticlePageScope(kw string, nodeid int, cp int, mp int, paths []string, order string) ApiJson {
+	if len(paths) == 0 {
+		return ApiJson{State: true, Msg: []ArticleResults{}, Count: 0}
+	}
+	where := "a.nodeid = b.id and a.uid = c.id and a.trash_at = 0 and a.title like ? and b.nodepath like ?"
+	param := []interface{}{"%" + kw + "%", "%," + Tools.ParseString(nodeid) + ",%"}
+	var scope []string
+	for _, path := range paths {
+		scope = append(scope, "b.nodepath like ?")
+		param = append(param, likeEscape(path)+"%")
+	}
+	where += " and (" + strings.Join(scope, " or ") + ")"
+	return articlePage(where, param, cp, mp, order)
 }
 
 /**
- * 删除文章
- * @method UserArticle
- * @param  {[type]} ids int[] [description]
+ * 前台可见文章的条件：不在回收站，已审核，到了发布时间，没有到下线时间，不在隐藏的节点下，需要传入两次当前时间
+ * @method ArticlePublicWhere
+ * @param  {[type]} prefix string 表别名，如"a."
  */
-func ArticleDele(ids []int) ApiJson {
-	err := DB.Where("id in (?) ", ids).Delete(Article{}).Error
-	if err != nil {
-		return ApiJson{State: false, Msg: err.Error()}
-	} else {
-		return ApiJson{State: true}
+func ArticlePublicWhere(prefix string) string {
+	return prefix + "trash_at = 0 and " + prefix + "pass = 1 and (" + prefix + "publish_at = 0 or " + prefix + "publish_at <= ?) and (" + prefix + "expire_at = 0 or " + prefix + "expire_at > ?) and " + prefix + "nodeid not in (select id from pz_node where hidden = 1)"
+}
+
+/**
+ * 前台获取文章，未发布的文章返回空
+ * @method ArticleGetPublic
+ * @param  {[type]} id int [description]
+ */
+func ArticleGetPublic(id int) ApiJson {
+	var article Article
+	now := time.Now().Unix()
+	DB.Where("id = ? and "+ArticlePublicWhere(""), id, now, now).First(&article)
+	if article.ID == 0 {
+		return ApiJson{State: false, Msg: "article is no exist"}
 	}
+	return ApiJson{State: true, Msg: article.Public()}
 }
 
 /**
- * 审核文章
- * @method ArticlePass
- * @param  {[type]} ids int[] [description]
+ * 定时发布：到了发布时间且审核通过的文章设置为已发布并清空发布时间，返回处理的文章id
+ * @method ArticlePublishDue
+ * @param  {[type]} now int64 [description]
  */
-func ArticlePass(ids []int, pass int) ApiJson {
-	err := DB.Model(Article{}).Where("id in (?) ", ids).UpdateColumns(map[string]interface{}{"pass": pass}).Error
-	if err != nil {
-		return ApiJson{State: false, Msg: err.Error()}
+func ArticlePublishDue(now int64) ([]int, error) {
+	var ids []int
+	where := "publish_at > 0 and publish_at <= ? and status in (?) and trash_at = 0"
+	status := []string{ArticleApproved, ArticlePublished}
+	DB.Model(Article{}).Where(where, now, status).Pluck("id", &ids)
+	if len(ids) == 0 {
+		return ids, nil
+	}
+	err := DB.Model(Article{}).Where("id in (?) and "+where, ids, now, status).UpdateColumns(map[string]interface{}{"pass": 1, "status": ArticlePublished, "publish_at": 0}).Error
+	return ids, err
+}
+
+/**
+ * 自动下线：到了下线时间的文章取消审核、归档并清空下线时间，返回处理的文章id
+ * @method ArticleExpireDue
+ * @param  {[type]} now int64 [description]
+ */
+func ArticleExpireDue(now int64) ([]int, error) {
+	var ids []int
+	DB.Model(Article{}).Where("expire_at > 0 and expire_at <= ?", now).Pluck("id", &ids)
+	if len(ids) == 0 {
+		return ids, nil
+	}
+	err := DB.Model(Article{}).Where("id in (?) and expire_at > 0 and expire_at <= ?", ids, now).UpdateColumns(map[string]interface{}{"pass": 0, "status": gorm.Expr("if(status = ?, ?, status)", ArticlePublished, ArticleArchived), "expire_at": 0}).Error
+	return ids, err
+}
+
+/**
+ * 修改编辑流程状态，只有当前状态为from时才会修改，pass随状态同步
+ * @method ArticleSetStatus
+ * @param  {[type]} id     int                    [description]
+ * @param  {[type]} from   string                 [description]
+ * @param  {[type]} to     string                 [description]
+ * @param  {[type]} fields map[string]interface{} 同时修改的其他字段
+ */
+func ArticleSetStatus(id int, from string, to string, fields map[string]interface{}) error {
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	fields["status"] = to
+	fields["pass"] = 0
+	if to == ArticlePublished {
+		fields["pass"] = 1
+	}
+	db := DB.Model(Article{}).Where("id = ? and status = ? and trash_at = 0", id, from).UpdateColumns(fields)
+	if db.Error != nil {
+		return db.Error
+	}
+	if db.RowsAffected == 0 {
+		return errors.New("article status has been changed")
+	}
+	return nil
+}
+
+/**
+ * 设置审核人，只有待审核的文章可以设置
+ * @method ArticleSetReviewer
+ * @param  {[type]} id       int [description]
+ * @param  {[type]} reviewer int [description]
+ */
+func ArticleSetReviewer(id int, reviewer int) error {
+	db := DB.Model(Article{}).Where("id = ? and status = ? and trash_at = 0", id, ArticleReview).UpdateColumns(map[string]interface{}{"reviewer": reviewer})
+	if db.Error != nil {
+		return db.Error
+	}
+	if db.RowsAffected == 0 {
+		return errors.New("article is not in review")
+	}
+	return nil
+}
+
+/**
+ * 待审核列表
+ * @method ArticleReviewQueue
+ * @param  {[type]} reviewer int      审核人id
+ * @param  {[type]} all      bool     是否包含未指定审核人的文章
+ * @param  {[type]} cp       int      [description]
+ * @param  {[type]} mp       int      [description]
+ * @param  {[type]} paths    []string 授权节点的路径，limited为true时只返回这些节点下的文章
+ * @param  {[type]} limited  bool     是否限制节点
+ */
+func ArticleReviewQueue(reviewer int, all bool, cp int, mp int, paths []string, limited bool) ApiJson {
+	var articles []Article
+	var count int
+	db := DB.Table("pz_article").Select("id,title,timg,brief,nodeid,uid,createtime,status,reviewer,review_note").Where("status = ? and trash_at = 0", ArticleReview)
+	if limited {
+		if len(paths) == 0 {
+			return ApiJson{State: true, Msg: []Article{}, Count: 0}
+		}
+		var scope []string
+		var param []interface{}
+		for _, path := range paths {
+			scope = append(scope, "nodepath like ?")
+			param = append(param, likeEscape(path)+"%")
+		}
+		db = db.Where("nodeid in (select id from pz_node where "+strings.Join(scope, " or ")+")", param...)
+	}
+	if all {
+		db = db.Where("reviewer = ? or reviewer = 0", reviewer)
 	} else {
-		return ApiJson{State: true}
+		db = db.Where("reviewer = ?", reviewer)
+	}
+	db.Count(&count).Order("id").Offset((cp - 1) * mp).Limit(mp).Find(&articles)
+	return ApiJson{State: true, Msg: articles, Count: count}
+}
+
+/**
+ * 获取文章的浏览数
+ * @method ArticleCountGet
+ * @param  {[type]} id int [description]
+ */
+func ArticleCountGet(id int) int {
+	var article Article
+	DB.Select("id,count").Where("id = ?", id).First(&article)
+	return article.Count
+}
+
+/**
+ * 批量增加文章的浏览数，一条语句更新所有文章，同时累加到当天的浏览数，用于重建今日和本周排行
+ * 同一批次已经写入过的文章跳过，写入记录和浏览数在同一个事务中，重复调用不会重复计数，返回这次实际增加的浏览数
+ * @method ArticleCountAdd
+ * @param  {[type]} deltas map[int]int 文章id=>增加的浏览数
+ * @param  {[type]} day    int         日期，如20160312
+ * @param  {[type]} batch  int64       写入批次
+ */
+func ArticleCountAdd(deltas map[int]int, day int, batch int64) (map[int]int, error) {
+	added := map[int]int{}
+	if len(deltas) == 0 {
+		return added, nil
+	}
+	var ids []int
+	for id := range deltas {
+		ids = append(ids, id)
 	}
+	tx := DB.Begin()
+	var done []int
+	if err := tx.Model(ArticleViewFlush{}).Where("batch = ? and articleid in (?)", batch, ids).Pluck("articleid", &done).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	applied := map[int]bool{}
+	for _, id := range done {
+		applied[id] = true
+	}
+	sql := "update pz_article set count = count + case id"
+	insert := "insert into pz_article_view_day (articleid, day, views) values "
+	flush := "insert into pz_article_view_flush (batch, articleid) values "
+	var param []interface{}
+	var values []string
+	var dayParam []interface{}
+	var flushParam []interface{}
+	ids = ids[:0]
+	for id, delta := range deltas {
+		if applied[id] {
+			continue
+		}
+		sql += " when ? then ?"
+		param = append(param, id, delta)
+		ids = append(ids, id)
+		values = append(values, "(?,?,?)")
+		dayParam = append(dayParam, id, day, delta)
+		flushParam = append(flushParam, batch, id)
+		added[id] = delta
+	}
+	if len(ids) == 0 {
+		tx.Rollback()
+		return added, nil
+	}
+	sql += " else 0 end where id in (?)"
+	//同一批次并发写入时主键冲突，整个事务回滚
+	if err := tx.Exec(flush+strings.Repeat("(?,?),", len(ids)-1)+"(?,?)", flushParam...).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Exec(sql, append(param, ids)...).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Exec(insert+strings.Join(values, ",")+" on duplicate key update views = views + values(views)", dayParam...).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+/**
+ * 按审核通过的评论重新计算文章的评论数
+ * @method ArticleCommentSync
+ * @param  {[type]} ids []int [description]
+ */
+func ArticleCommentSync(ids []int) error {
+	return DB.Exec("update pz_article set comment = (select count(*) from pz_comment where pz_comment.articleid = pz_article.id and pz_comment.pass = 1) where id in (?)", ids).Error
+}
+
+/**
+ * 只更新文章内容，用于批量处理已有数据
+ * @method ArticleContentSet
+ * @param  {[type]} id      int    [description]
+ * @param  {[type]} content string [description]
+ */
+func ArticleContentSet(id int, content string) error {
+	return DB.Model(Article{}).Where("id = ?", id).UpdateColumn("content", content).Error
+}
+
+/**
+ * 更新文章的摘要、字数和阅读时间，用于批量处理已有数据
+ * @method ArticleStatsSet
+ * @param  {[type]} id       int    [description]
+ * @param  {[type]} brief    string [description]
+ * @param  {[type]} words    int    [description]
+ * @param  {[type]} readtime int    [description]
+ */
+func ArticleStatsSet(id int, brief string, words int, readtime int) error {
+	return DB.Model(Article{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{"brief": brief, "words": words, "readtime": readtime}).Error
+}
+
+//////////私有方法
+//按条件分页查询文章，条件中的表别名a为文章，b为节点，c为用户
+func articlePage(where string, param []interface{}, cp int, mp int, order string) ApiJson {
+	if order == "" {
+		order = NodeSorts["new"]
+	}
+	var articles []ArticleResults
+	var count int
+	DB.Raw("select count(*) from pz_article as a,pz_node as b,pz_user as c where "+where, param...).Row().Scan(&count)
+	DB.Raw("select a.*,b.`name` as nodename,c.username from pz_article as a,pz_node as b,pz_user as c where "+where+" order by "+order+" limit ? offset ?", append(param, mp, (cp-1)*mp)...).Scan(&articles)
+	return ApiJson{State: true, Msg: articles, Count: count}
 }
@@ -0,0 +1,15 @@
+package model
+
+import "testing"
+
+//前台输出的文章不能带审核人、审核意见、markdown原文和删除人
+func TestArticlePublic(t *testing.T) {
+	article := Article{ID: 1, Title: "title", Reviewer: 2, ReviewNote: "note", Markdown: "# title", TrashBy: 3}
+	got := ArticlesPublic([]ArticleResults{{Article: article}})[0].Article
+	if got.Reviewer != 0 || got.ReviewNote != "" || got.Markdown != "" || got.TrashBy != 0 {
+		t.Errorf("ArticlesPublic() kept admin fields: %+v", got)
+	}
+	if got.ID != 1 || got.Title != "title" {
+		t.Errorf("ArticlesPublic() changed public fields: %+v", got)
+	}
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"gopkg.in/mgo.v2/bson"
+)
+
+type Audit struct {
+	Id       bson.ObjectId `bson:"_id" json:"id"`
+	Uid      int           `bson:"uid" json:"uid"`           //操作人id
+	Username string        `bson:"username" json:"username"` //操作人用户名
+	Action   string        `bson:"action" json:"action"`     //操作，如article.update
+	Ids      []int         `bson:"ids" json:"ids"`           //操作对象的id
+	Before   interface{}   `bson:"before" json:"before"`     //操作前的数据
+	After    interface{}   `bson:"after" json:"after"`       //操作后的数据
+	Ip       string        `bson:"ip" json:"ip"`             //客户端ip
+	Time     int64         `bson:"time" json:"time"`         //操作时间
+}
+
+/**
+ * 创建audit
+ * @method AuditCreate
+ * @param  {[type]}   audit Audit [description]
+ */
+func AuditCreate(audit Audit) error {
+	session, c := Modb.SwitchC("audits")
+	defer session.Close()
+	if audit.Id == "" {
+		audit.Id = bson.NewObjectId()
+	}
+	return c.Insert(audit)
+}
+
+/**
+ * 获取审计日志列表，按时间降序
+ * @method AuditPage
+ * @param  {[type]} query bson.M [description]
+ * @param  {[type]} cp    int    [description]
+ * @param  {[type]} mp    int    [description]
+ */
+func AuditPage(query bson.M, cp int, mp int) ApiJson {
+	var audits []Audit
+	session, c := Modb.SwitchC("audits")
+	defer session.Close()
+	count, err := c.Find(query).Count()
+	if err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	err = c.Find(query).Sort("-time", "-_id").Skip((cp - 1) * mp).Limit(mp).All(&audits) //降序
+	if err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	return ApiJson{State: true, Msg: audits, Count: count}
+}
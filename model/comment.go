@@ -1,14 +1,24 @@
 package model
 
-import ()
+import (
+	"time"
+)
+
+//评论审核状态，前台只显示审核通过的评论
+const (
+	CommentPending  = 0 //待审核
+	CommentApproved = 1 //审核通过
+	CommentRejected = 2 //审核不通过
+)
 
 type Comment struct {
 	Id  int `json:"id" gorm:"primary_key;AUTO_INCREMENT" validate:"omitempty,min=1"`  //主键id
-	Articleid  int `json:"articleid" sql:"default:0" validate:"omitempty,min=1,"`  //文章id
-	Addtime  int `json:"addtime" sql:"default:0" validate:"omitempty,min=1,"`  //添加时间
-	Content  string `json:"content" sql:"type:varchar(1000);default:`  //评论内容
-	Uid  int `json:"uid" sql:"default:0" validate:"omitempty,min=1,"`  //用户id
-	Username  string `json:"username" sql:"type:varchar(30);default:`  //用户昵称
+	Articleid  int `json:"articleid" sql:"default:0" validate:"omitempty,min=1"`  //文章id
+	Addtime  int `json:"addtime" sql:"default:0" validate:"omitempty,min=1"`  //添加时间
+	Content  string `json:"content" sql:"type:varchar(1000);default:''"`  //评论内容
+	Uid  int `json:"uid" sql:"default:0" validate:"omitempty,min=1"`  //用户id
+	Username  string `json:"username" sql:"type:varchar(30);default:''"`  //用户昵称
+	Pass  int `json:"pass"`  //审核状态，见CommentPending等，节点评论需要审核时为0，不设默认值以免gorm创建时忽略0
 }
 
 func (u Comment) TableName() string {
@@ -17,59 +27,103 @@ func (u Comment) TableName() string {
 /**
  * 获取comment
  * @method CommentGet
- * @param  {[type]} id int [description]
+ * @param  {[type]} id     int  [description]
+ * @param  {[type]} public bool 前台读取，只返回前台可见的文章下审核通过的评论
  */
-func CommentGet(id int) ApiJson {
+func CommentGet(id int, public bool) ApiJson {
 	var comment Comment
+	if public {
+		now := time.Now().Unix()
+		DB.Raw("select c.* from pz_comment as c,pz_article as a where c.articleid = a.id and c.id = ? and c.pass = ? and "+ArticlePublicWhere("a."), id, CommentApproved, now, now).Scan(&comment)
+		if comment.Id == 0 {
+			return ApiJson{State: false, Msg: "comment is no exist"}
+		}
+		return ApiJson{State: true, Msg: comment}
+	}
 	DB.First(&comment, id)
 	return ApiJson{State: true, Msg:comment}
 }
-/**
- * 更新comment
- * @method CommentUpdate
- * @param  {[type]}    comment Comment [description]
- */
-func CommentUpdate(comment Comment) ApiJson {
-	 err := DB.Model(&comment).UpdateColumns(map[string]interface{}{"articleid": comment.Articleid, "addtime": comment.Addtime, "content": comment.Content, "uid": comment.Uid, "username": comment.Username, }).Error
-	 if err != nil {
-		 return ApiJson{State: false, Msg: err}
-	 } else {
-		 return ApiJson{State: true}
-	 }
-}
 /**
  * 创建comment
  * @method CommentCreate
  * @param  {[type]}    comment Comment [description]
  */
 func CommentCreate(comment Comment) ApiJson {
-	DB.Save(&comment)
+	if err := DB.Create(&comment).Error; err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
 	return ApiJson{State:true, Msg:comment.Id}
 }
 /**
  * page comment
  * @method CommentPage
- * @param  {[type]}  kw string [description]
- * @param  {[type]}  cp int    [description]
- * @param  {[type]}  mp int    [description]
+ * @param  {[type]}  kw   string [description]
+ * @param  {[type]}  cp   int    [description]
+ * @param  {[type]}  mp   int    [description]
+ * @param  {[type]}  pass int    审核状态，-1表示不限
  */
-func CommentPage(kw string, cp int, mp int) ApiJson {
+func CommentPage(kw string, cp int, mp int, pass int) ApiJson {
 	var comments []Comment
 	var count int
-	DB.Table("pz_comment").Select("*").Where("title like ?",  "%"+kw+"%").Count(&count).Offset((cp - 1) * mp).Limit(mp).Find(&comments)
+	db := DB.Table("pz_comment").Select("*").Where("content like ?",  "%"+kw+"%")
+	if pass >= 0 {
+		db = db.Where("pass = ?", pass)
+	}
+	db.Count(&count).Order("id desc").Offset((cp - 1) * mp).Limit(mp).Find(&comments)
 	return ApiJson{State: true, Msg:comments,Count:count}
 }
 
+/**
+ * 设置评论的审核状态
+ * @method CommentPassSet
+ * @param  {[type]} ids  []int [description]
+ * @param  {[type]} pass int   [description]
+ */
+func CommentPassSet(ids []int, pass int) error {
+	return DB.Model(Comment{}).Where("id in (?)", ids).UpdateColumn("pass", pass).Error
+}
+
 /**
  * 删除文章评论
  * @method CommentDele
  * @param  {[type]} ids int[] [description]
  */
-func CommentDel(id int, uid int) ApiJson {
-	err := DB.Where("id = ? and uid = ?", id, uid).Delete(Comment{}).Error
+func CommentDel(id int) ApiJson {
+	err := DB.Where("id = ?", id).Delete(Comment{}).Error
 	if err != nil {
 		return ApiJson{State: false, Msg: err.Error()}
 	} else {
 		return ApiJson{State: true}
 	}
 }
+
+/**
+ * 所有评论id，用于批量处理
+ * @method CommentIds
+ */
+func CommentIds() ([]int, error) {
+	var ids []int
+	err := DB.Model(Comment{}).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+/**
+ * 根据id数组获取评论
+ * @method CommentList
+ * @param  {[type]} ids []int [description]
+ */
+func CommentList(ids []int) []Comment {
+	var comments []Comment
+	DB.Where("id in (?) ", ids).Find(&comments)
+	return comments
+}
+
+/**
+ * 只更新评论内容
+ * @method CommentContentSet
+ * @param  {[type]} id      int    [description]
+ * @param  {[type]} content string [description]
+ */
+func CommentContentSet(id int, content string) error {
+	return DB.Model(Comment{}).Where("id = ?", id).UpdateColumn("content", content).Error
+}
@@ -0,0 +1,220 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+//菜单项的类型
+const (
+	MenuNode    = "node"    //链接到节点
+	MenuArticle = "article" //链接到文章
+	MenuLink    = "link"    //外部链接
+)
+
+type Menu struct {
+	ID         int    `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	Name       string `json:"name" sql:"type:varchar(30);default:''" validate:"required,max=30"` //调用标识，如main、footer，唯一
+	Title      string `json:"title" sql:"type:varchar(50);default:''" validate:"max=50"`
+	Createtime int64  `json:"createtime" sql:"default:0"`
+}
+
+type MenuItem struct {
+	ID       int    `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	Menuid   int    `json:"menuid" sql:"default:0"`
+	Pid      int    `json:"pid" sql:"default:0"`                                       //上级菜单项id，0表示顶级
+	Kind     string `json:"kind" sql:"type:varchar(10);default:''"`                    //node、article或link
+	Targetid int    `json:"targetid" sql:"default:0"`                                  //节点或文章id
+	Url      string `json:"url" sql:"type:varchar(255);default:''" validate:"max=255"` //外部链接地址
+	Title    string `json:"title" sql:"type:varchar(50);default:''" validate:"max=50"` //为空时使用节点名称或文章标题
+	Sort     int    `json:"sort" sql:"default:0"`                                      //同级菜单项中小的在前
+}
+
+//拖动排序后菜单项的位置
+type MenuItemOrder struct {
+	ID   int `json:"id"`
+	Pid  int `json:"pid"`
+	Sort int `json:"sort"`
+}
+
+func (m Menu) TableName() string {
+	return "pz_menu"
+}
+
+func (m MenuItem) TableName() string {
+	return "pz_menu_item"
+}
+
+/**
+ * 根据id获取菜单，不存在时ID为0
+ * @method MenuFind
+ * @param  {[type]} id int [description]
+ */
+func MenuFind(id int) Menu {
+	var menu Menu
+	if id > 0 {
+		DB.First(&menu, id)
+	}
+	return menu
+}
+
+/**
+ * 根据调用标识获取菜单，不存在时ID为0
+ * @method MenuFindByName
+ * @param  {[type]} name string [description]
+ */
+func MenuFindByName(name string) Menu {
+	var menu Menu
+	DB.Where("name = ?", name).First(&menu)
+	return menu
+}
+
+/**
+ * 所有菜单
+ * @method MenuAll
+ */
+func MenuAll() []Menu {
+	var menus []Menu
+	DB.Order("id").Find(&menus)
+	return menus
+}
+
+/**
+ * 创建菜单
+ * @method MenuCreate
+ * @param  {[type]} menu Menu [description]
+ */
+func MenuCreate(menu Menu) ApiJson {
+	if MenuFindByName(menu.Name).ID > 0 {
+		return ApiJson{State: false, Msg: "menu name is exist"}
+	}
+	menu.ID = 0
+	menu.Createtime = time.Now().Unix()
+	if err := DB.Create(&menu).Error; err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	return ApiJson{State: true, Msg: menu.ID}
+}
+
+/**
+ * 更新菜单的调用标识和名称
+ * @method MenuUpdate
+ * @param  {[type]} menu Menu [description]
+ */
+func MenuUpdate(menu Menu) ApiJson {
+	if exist := MenuFindByName(menu.Name); exist.ID > 0 && exist.ID != menu.ID {
+		return ApiJson{State: false, Msg: "menu name is exist"}
+	}
+	if err := DB.Model(&menu).UpdateColumns(map[string]interface{}{"name": menu.Name, "title": menu.Title}).Error; err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	return ApiJson{State: true}
+}
+
+/**
+ * 删除菜单和它的所有菜单项
+ * @method MenuDele
+ * @param  {[type]} id int [description]
+ */
+func MenuDele(id int) error {
+	tx := DB.Begin()
+	if err := tx.Where("menuid = ?", id).Delete(MenuItem{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("id = ?", id).Delete(Menu{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+/**
+ * 根据id获取菜单项，不存在时ID为0
+ * @method MenuItemFind
+ * @param  {[type]} id int [description]
+ */
+func MenuItemFind(id int) MenuItem {
+	var item MenuItem
+	if id > 0 {
+		DB.First(&item, id)
+	}
+	return item
+}
+
+/**
+ * 菜单的所有菜单项，按顺序排列
+ * @method MenuItems
+ * @param  {[type]} menuid int [description]
+ */
+func MenuItems(menuid int) []MenuItem {
+	var items []MenuItem
+	DB.Where("menuid = ?", menuid).Order("sort,id").Find(&items)
+	return items
+}
+
+/**
+ * 创建菜单项，排在同级菜单项的最后
+ * @method MenuItemCreate
+ * @param  {[type]} item MenuItem [description]
+ */
+func MenuItemCreate(item MenuItem) ApiJson {
+	var sort struct{ Max int }
+	DB.Raw("select ifnull(max(sort),0) as max from pz_menu_item where menuid = ? and pid = ?", item.Menuid, item.Pid).Scan(&sort)
+	item.ID = 0
+	item.Sort = sort.Max + 1
+	if err := DB.Create(&item).Error; err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	return ApiJson{State: true, Msg: item.ID}
+}
+
+/**
+ * 更新菜单项的链接和名称，位置通过MenuItemSort修改
+ * @method MenuItemUpdate
+ * @param  {[type]} item MenuItem [description]
+ */
+func MenuItemUpdate(item MenuItem) ApiJson {
+	if err := DB.Model(&item).UpdateColumns(map[string]interface{}{"kind": item.Kind, "targetid": item.Targetid, "url": item.Url, "title": item.Title}).Error; err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	return ApiJson{State: true}
+}
+
+/**
+ * 删除菜单项和它的所有下级菜单项，返回删除的菜单项id
+ * @method MenuItemDele
+ * @param  {[type]} id int [description]
+ */
+func MenuItemDele(id int) ([]int, error) {
+	item := MenuItemFind(id)
+	if item.ID == 0 {
+		return nil, errors.New("menu item is no exist")
+	}
+	children := map[int][]int{}
+	for _, other := range MenuItems(item.Menuid) {
+		children[other.Pid] = append(children[other.Pid], other.ID)
+	}
+	ids := []int{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids, DB.Where("id in (?) ", ids).Delete(MenuItem{}).Error
+}
+
+/**
+ * 保存拖动排序后的位置，菜单项必须属于该菜单
+ * @method MenuItemSort
+ * @param  {[type]} menuid int             [description]
+ * @param  {[type]} orders []MenuItemOrder [description]
+ */
+func MenuItemSort(menuid int, orders []MenuItemOrder) error {
+	tx := DB.Begin()
+	for _, order := range orders {
+		if err := tx.Model(MenuItem{}).Where("id = ? and menuid = ?", order.ID, menuid).UpdateColumns(map[string]interface{}{"pid": order.Pid, "sort": order.Sort}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit().Error
+}
@@ -1,7 +1,7 @@
 package model
 
 import (
-	"fmt"
+	"log"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/gorm"
 	"pizzaCmsApi/config"
@@ -27,7 +27,8 @@ func init() {
 	var err error
 	DB, err = gorm.Open("mysql", Config.Mysql.Connect)
 	if err != nil {
-		panic(fmt.Sprintf("No error should happen when connecting to  database, but got err=%+v", err))
+		//和redis、mongodb一样，连不上时不退出，使用时再连接，不需要数据库的单元测试也可以运行
+		log.Printf("connect to database error: %+v", err)
 	}
 	DB.DB().SetMaxIdleConns(Config.Mysql.MaxIdle) //最大连接数
 	DB.DB().SetMaxOpenConns(Config.Mysql.MaxOpen)
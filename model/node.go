@@ -0,0 +1,364 @@
+package model
+
+import (
+	"errors"
+	"github.com/jinzhu/gorm"
+	"strings"
+)
+
+//节点设置的可选值，设置为空时继承上级节点
+const (
+	NodePublic          = "public"    //前台可见
+	NodeHidden          = "hidden"    //前台隐藏，节点和下级节点的文章都不在前台显示
+	NodeCommentOn       = "enabled"   //允许评论
+	NodeCommentModerate = "moderated" //评论需要审核后显示
+	NodeCommentOff      = "disabled"  //关闭评论
+)
+
+//文章排序方式和对应的order by
+var NodeSorts = map[string]string{
+	"new":     "a.id desc",
+	"old":     "a.id asc",
+	"reco":    "a.reco desc,a.id desc",
+	"view":    "a.count desc,a.id desc",
+	"comment": "a.comment desc,a.id desc",
+}
+
+type Node struct {
+	ID          int    `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	Pid         int    `json:"pid" sql:"default:0"` //上级节点id，0表示顶级
+	Name        string `json:"name" sql:"type:varchar(50);default:''" validate:"required,max=50"`
+	Brief       string `json:"brief" sql:"type:varchar(255);default:''" validate:"max=255"`
+	Nodepath    string `json:"nodepath" sql:"type:varchar(255);default:''"` //从顶级到自身的id路径，如,1,3,9,，由程序维护
+	Link        string `json:"link" sql:"type:varchar(100);default:''" validate:"max=100"`
+	Weight      int    `json:"weight" sql:"default:0"`                                             //权重，同级节点中权重大的在前
+	Visibility  string `json:"visibility" sql:"type:varchar(10);default:''"`                       //public或hidden，为空时继承上级节点
+	Hidden      int    `json:"hidden" sql:"default:0"`                                             //继承后的结果，1表示前台隐藏，由程序维护
+	Comment     string `json:"comment" sql:"type:varchar(10);default:''"`                          //评论策略enabled、moderated、disabled，为空时继承
+	Sort        string `json:"sort" sql:"type:varchar(10);default:''"`                             //文章默认排序，见NodeSorts，为空时继承
+	Pagesize    int    `json:"pagesize" sql:"default:0" validate:"min=0,max=50"`                   //每页文章数，0表示继承
+	SeoTitle    string `json:"seo_title" sql:"type:varchar(100);default:''" validate:"max=100"`    //为空时使用节点名称，不继承
+	SeoKeywords string `json:"seo_keywords" sql:"type:varchar(255);default:''" validate:"max=255"` //为空时继承
+}
+
+func (n Node) TableName() string {
+	return "pz_node"
+}
+
+/**
+ * 根据id获取节点
+ * @method NodeGet
+ * @param  {[type]} id int [description]
+ */
+func NodeGet(id int) ApiJson {
+	node := NodeFind(id)
+	if node.ID == 0 {
+		return ApiJson{State: false, Msg: "node is no exist"}
+	}
+	return ApiJson{State: true, Msg: node}
+}
+
+/**
+ * 根据id获取节点，不存在时ID为0
+ * @method NodeFind
+ * @param  {[type]} id int [description]
+ */
+func NodeFind(id int) Node {
+	var node Node
+	if id > 0 {
+		DB.First(&node, id)
+	}
+	return node
+}
+
+/**
+ * 根据id数组获取节点
+ * @method NodeList
+ * @param  {[type]} ids []int [description]
+ */
+func NodeList(ids []int) []Node {
+	var nodes []Node
+	DB.Where("id in (?) ", ids).Find(&nodes)
+	return nodes
+}
+
+/**
+ * 节点及所有后代节点下的文章id
+ * @method NodeArticleIds
+ * @param  {[type]} id int [description]
+ */
+func NodeArticleIds(id int) []int {
+	return nodeArticleIds(DB, NodeFind(id))
+}
+
+/**
+ * 所有节点，按权重降序
+ * @method NodeAll
+ */
+func NodeAll() []Node {
+	var nodes []Node
+	DB.Order("weight desc,id").Find(&nodes)
+	return nodes
+}
+
+/**
+ * 创建节点，nodepath根据上级节点生成
+ * @method NodeCreate
+ * @param  {[type]} node Node [description]
+ */
+func NodeCreate(node Node) ApiJson {
+	tx := DB.Begin()
+	parentPath := ","
+	if node.Pid > 0 {
+		var parent Node
+		if tx.Set("gorm:query_option", "FOR UPDATE").First(&parent, node.Pid).RecordNotFound() {
+			tx.Rollback()
+			return ApiJson{State: false, Msg: "parent node is no exist"}
+		}
+		parentPath = parent.Nodepath
+	}
+	node.ID = 0
+	node.Nodepath = ""
+	if err := tx.Create(&node).Error; err != nil {
+		tx.Rollback()
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	node.Nodepath = parentPath + Tools.ParseString(node.ID) + ","
+	if err := tx.Model(&node).UpdateColumn("nodepath", node.Nodepath).Error; err != nil {
+		tx.Rollback()
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	if err := nodeHiddenSync(tx); err != nil {
+		tx.Rollback()
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	return ApiJson{State: true, Msg: node.ID}
+}
+
+/**
+ * 更新节点和节点设置，不修改上级节点和nodepath
+ * @method NodeUpdate
+ * @param  {[type]} node Node [description]
+ */
+func NodeUpdate(node Node) ApiJson {
+	tx := DB.Begin()
+	err := tx.Model(&node).UpdateColumns(map[string]interface{}{"name": node.Name, "brief": node.Brief, "link": node.Link, "weight": node.Weight, "visibility": node.Visibility, "comment": node.Comment, "sort": node.Sort, "pagesize": node.Pagesize, "seo_title": node.SeoTitle, "seo_keywords": node.SeoKeywords}).Error
+	if err == nil {
+		err = nodeHiddenSync(tx)
+	}
+	if err != nil {
+		tx.Rollback()
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	return ApiJson{State: true}
+}
+
+/**
+ * 删除节点，有下级节点或文章时必须指定转移到的节点to，返回受影响的文章id
+ * @method NodeDele
+ * @param  {[type]} id int [description]
+ * @param  {[type]} to int 下级节点和文章转移到的节点，0表示不转移
+ */
+func NodeDele(id int, to int) ([]int, error) {
+	return nodeRemove(id, to, false)
+}
+
+/**
+ * 合并节点：下级节点移到目标节点下，文章改为属于目标节点，然后删除原节点，返回受影响的文章id
+ * @method NodeMerge
+ * @param  {[type]} from int 被合并的节点id
+ * @param  {[type]} to   int 目标节点id
+ */
+func NodeMerge(from int, to int) ([]int, error) {
+	if to <= 0 {
+		return nil, errors.New("target node is no exist")
+	}
+	return nodeRemove(from, to, true)
+}
+
+/**
+ * 移动节点到新的上级节点下，节点和所有后代节点的nodepath一起更新，返回子树中的文章id
+ * @method NodeMove
+ * @param  {[type]} id  int [description]
+ * @param  {[type]} pid int 新的上级节点id，0表示移到顶级
+ */
+func NodeMove(id int, pid int) ([]int, error) {
+	tx := DB.Begin()
+	var node Node
+	if tx.Set("gorm:query_option", "FOR UPDATE").First(&node, id).RecordNotFound() {
+		tx.Rollback()
+		return nil, errors.New("node is no exist")
+	}
+	parentPath := ","
+	if pid > 0 {
+		var parent Node
+		if tx.Set("gorm:query_option", "FOR UPDATE").First(&parent, pid).RecordNotFound() {
+			tx.Rollback()
+			return nil, errors.New("parent node is no exist")
+		}
+		if nodeContains(node, parent) {
+			tx.Rollback()
+			return nil, errors.New("can not move a node into itself or its children")
+		}
+		parentPath = parent.Nodepath
+	}
+	articleids := nodeArticleIds(tx, node)
+	if err := nodeRepath(tx, node.Nodepath, parentPath+Tools.ParseString(node.ID)+",", 0); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Model(&node).UpdateColumn("pid", pid).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := nodeHiddenSync(tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return articleids, tx.Commit().Error
+}
+
+//////////私有方法
+//删除节点，merge为true或节点有下级节点、文章时，把它们转移到to
+func nodeRemove(id int, to int, merge bool) ([]int, error) {
+	tx := DB.Begin()
+	var node Node
+	if tx.Set("gorm:query_option", "FOR UPDATE").First(&node, id).RecordNotFound() {
+		tx.Rollback()
+		return nil, errors.New("node is no exist")
+	}
+	var children, articles int
+	tx.Model(Node{}).Where("pid = ?", id).Count(&children)
+	tx.Model(Article{}).Where("nodeid = ?", id).Count(&articles)
+	if to == 0 && (children > 0 || articles > 0) {
+		tx.Rollback()
+		return nil, errors.New("node has children or articles, choose a node to move them to")
+	}
+	articleids := nodeArticleIds(tx, node)
+	if merge || children > 0 || articles > 0 {
+		var target Node
+		if tx.Set("gorm:query_option", "FOR UPDATE").First(&target, to).RecordNotFound() {
+			tx.Rollback()
+			return nil, errors.New("target node is no exist")
+		}
+		if nodeContains(node, target) {
+			tx.Rollback()
+			return nil, errors.New("target node can not be the node or its children")
+		}
+		//下级节点的路径前缀从原节点换成目标节点
+		if err := nodeRepath(tx, node.Nodepath, target.Nodepath, node.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := tx.Model(Node{}).Where("pid = ?", node.ID).UpdateColumn("pid", target.ID).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := tx.Model(Article{}).Where("nodeid = ?", id).UpdateColumn("nodeid", to).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := tx.Delete(&node).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := nodeHiddenSync(tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return articleids, tx.Commit().Error
+}
+
+//把nodepath以from开头的节点改成以to开头，exclude为不修改的节点id
+func nodeRepath(tx *gorm.DB, from string, to string, exclude int) error {
+	if from == "" {
+		return errors.New("nodepath is empty")
+	}
+	var nodes []Node
+	if err := tx.Select("id,nodepath").Where("nodepath like ? and id <> ?", from+"%", exclude).Find(&nodes).Error; err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		nodepath, ok := nodepathRewrite(node.Nodepath, from, to)
+		if !ok {
+			continue
+		}
+		if err := tx.Model(&node).UpdateColumn("nodepath", nodepath).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//把以from开头的节点路径改成以to开头，不是以from开头时返回false，如",1,3,9,"从",1,3,"改到",2,"得到",2,9,"
+func nodepathRewrite(nodepath string, from string, to string) (string, bool) {
+	if from == "" || !strings.HasPrefix(nodepath, from) {
+		return nodepath, false
+	}
+	return to + nodepath[len(from):], true
+}
+
+// target是否是node自身或后代节点，用于防止把节点移到自己下面形成环
+func nodeContains(node Node, target Node) bool {
+	return target.ID == node.ID || strings.Contains(target.Nodepath, ","+Tools.ParseString(node.ID)+",")
+}
+
+//节点及所有后代节点下的文章id
+func nodeArticleIds(tx *gorm.DB, node Node) []int {
+	var ids []int
+	if node.Nodepath == "" {
+		tx.Model(Article{}).Where("nodeid = ?", node.ID).Pluck("id", &ids)
+		return ids
+	}
+	tx.Table("pz_article as a").Joins("join pz_node as b on a.nodeid = b.id").Where("b.nodepath like ?", node.Nodepath+"%").Pluck("a.id", &ids)
+	return ids
+}
+
+//按visibility的继承关系重新计算所有节点的hidden，节点移动、删除后下级节点的结果也可能变化
+func nodeHiddenSync(tx *gorm.DB) error {
+	var nodes []Node
+	if err := tx.Select("id,nodepath,visibility,hidden").Find(&nodes).Error; err != nil {
+		return err
+	}
+	visibility := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		visibility[Tools.ParseString(node.ID)] = node.Visibility
+	}
+	var hide, show []int
+	for _, node := range nodes {
+		hidden := 0
+		ids := strings.Split(strings.Trim(node.Nodepath, ","), ",")
+		for i := len(ids) - 1; i >= 0; i-- {
+			if v := visibility[ids[i]]; v != "" {
+				if v == NodeHidden {
+					hidden = 1
+				}
+				break
+			}
+		}
+		if hidden == node.Hidden {
+			continue
+		}
+		if hidden == 1 {
+			hide = append(hide, node.ID)
+		} else {
+			show = append(show, node.ID)
+		}
+	}
+	if len(hide) > 0 {
+		if err := tx.Model(Node{}).Where("id in (?) ", hide).UpdateColumn("hidden", 1).Error; err != nil {
+			return err
+		}
+	}
+	if len(show) > 0 {
+		return tx.Model(Node{}).Where("id in (?) ", show).UpdateColumn("hidden", 0).Error
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package model
+
+import "testing"
+
+func TestNodepathRewrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodepath string
+		from     string
+		to       string
+		want     string
+		ok       bool
+	}{
+		{"move node", ",1,3,", ",1,3,", ",2,3,", ",2,3,", true},
+		{"move descendant", ",1,3,9,", ",1,3,", ",2,3,", ",2,3,9,", true},
+		{"move to top", ",1,3,9,", ",1,3,", ",3,", ",3,9,", true},
+		{"merge children", ",1,3,9,", ",1,3,", ",5,", ",5,9,", true},
+		{"sibling untouched", ",1,4,", ",1,3,", ",2,3,", ",1,4,", false},
+		{"id prefix untouched", ",1,30,", ",1,3,", ",2,3,", ",1,30,", false},
+		{"ancestor untouched", ",1,", ",1,3,", ",2,3,", ",1,", false},
+		{"empty from", ",1,", "", ",2,", ",1,", false},
+	}
+	for _, tt := range tests {
+		got, ok := nodepathRewrite(tt.nodepath, tt.from, tt.to)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: nodepathRewrite() = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNodeContains(t *testing.T) {
+	tests := []struct {
+		nodepath string
+		id       int
+		want     bool
+	}{
+		{",1,3,9,", 1, true},
+		{",1,3,9,", 3, true},
+		{",1,3,9,", 9, true},
+		{",1,3,9,", 2, false},
+		{",1,30,", 3, false},
+		{",13,", 3, false},
+		{",13,", 1, false},
+		{"", 1, false},
+	}
+	for _, tt := range tests {
+		if got := nodeContains(Node{ID: tt.id}, Node{ID: 100, Nodepath: tt.nodepath}); got != tt.want {
+			t.Errorf("nodeContains(%d, %q) = %v, want %v", tt.id, tt.nodepath, got, tt.want)
+		}
+	}
+}
+
+func TestNodeContainsSelf(t *testing.T) {
+	if !nodeContains(Node{ID: 3, Nodepath: ",1,3,"}, Node{ID: 3, Nodepath: ",1,3,"}) {
+		t.Error("nodeContains() = false for the node itself")
+	}
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"time"
+)
+
+type ArticleRank struct {
+	ID         int    `json:"id"`
+	Nodepath   string `json:"nodepath"`
+	Count      int    `json:"count"`
+	Reco       int    `json:"reco"`
+	Comment    int    `json:"comment"`
+	Createtime int64  `json:"createtime"`
+}
+
+/**
+ * 前台可见文章的排行数据，用于重建排行榜
+ * @method ArticleRankAll
+ */
+func ArticleRankAll() []ArticleRank {
+	var ranks []ArticleRank
+	now := time.Now().Unix()
+	DB.Raw("select a.id,b.nodepath,a.count,a.reco,a.comment,a.createtime from pz_article as a left join pz_node as b on a.nodeid = b.id where "+ArticlePublicWhere("a."), now, now).Scan(&ranks)
+	return ranks
+}
+
+/**
+ * 文章所在节点的路径，如",1,3,9,"
+ * @method ArticleNodePaths
+ * @param  {[type]} ids []int [description]
+ */
+func ArticleNodePaths(ids []int) map[int]string {
+	var ranks []ArticleRank
+	DB.Raw("select a.id,b.nodepath from pz_article as a left join pz_node as b on a.nodeid = b.id where a.id in (?)", ids).Scan(&ranks)
+	paths := map[int]string{}
+	for _, rank := range ranks {
+		paths[rank.ID] = rank.Nodepath
+	}
+	return paths
+}
+
+/**
+ * 指定文章的排行数据
+ * @method ArticleRankList
+ * @param  {[type]} ids []int [description]
+ */
+func ArticleRankList(ids []int) []ArticleRank {
+	var ranks []ArticleRank
+	DB.Raw("select a.id,b.nodepath,a.count,a.reco,a.comment,a.createtime from pz_article as a left join pz_node as b on a.nodeid = b.id where a.id in (?)", ids).Scan(&ranks)
+	return ranks
+}
+
+type ArticleViewDay struct {
+	Articleid int `json:"articleid" sql:"default:0"`
+	Day       int `json:"day" sql:"default:0"`   //日期，如20160312
+	Views     int `json:"views" sql:"default:0"` //当天的浏览数
+}
+
+func (a ArticleViewDay) TableName() string {
+	return "pz_article_view_day"
+}
+
+/**
+ * 某一天有浏览的文章，包含节点路径，用于重建今日和本周排行
+ * @method ArticleViewDayList
+ * @param  {[type]} day int 日期，如20160312
+ */
+func ArticleViewDayList(day int) []ArticleRank {
+	var ranks []ArticleRank
+	DB.Raw("select a.id,b.nodepath,v.views as count from pz_article_view_day as v,pz_article as a left join pz_node as b on a.nodeid = b.id where v.articleid = a.id and v.day = ?", day).Scan(&ranks)
+	return ranks
+}
+
+/**
+ * 删除某一天之前的浏览数
+ * @method ArticleViewDayPurge
+ * @param  {[type]} day int 日期，如20160312
+ */
+func ArticleViewDayPurge(day int) error {
+	return DB.Where("day < ?", day).Delete(ArticleViewDay{}).Error
+}
+
+type ArticleViewFlush struct {
+	Batch     int64 `json:"batch" sql:"default:0"` //写入批次
+	Articleid int   `json:"articleid" sql:"default:0"`
+}
+
+func (a ArticleViewFlush) TableName() string {
+	return "pz_article_view_flush"
+}
+
+/**
+ * 删除某个批次之前的写入记录
+ * @method ArticleViewFlushPurge
+ * @param  {[type]} batch int64 [description]
+ */
+func ArticleViewFlushPurge(batch int64) error {
+	return DB.Where("batch < ?", batch).Delete(ArticleViewFlush{}).Error
+}
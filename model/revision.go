@@ -0,0 +1,126 @@
+package model
+
+import ()
+
+type Revision struct {
+	ID         int    `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	Articleid  int    `json:"articleid" sql:"default:0"`                  //文章id
+	Version    int    `json:"version" sql:"default:0"`                    //版本号，从1开始
+	Uid        int    `json:"uid" sql:"default:0"`                        //修改人id
+	Username   string `json:"username" sql:"type:varchar(30);default:''"` //修改人用户名
+	Createtime int64  `json:"createtime"`                                 //修改时间
+	Title      string `json:"title" sql:"type:varchar(50);default:''"`    //以下为文章字段快照
+	Timg       string `json:"timg" sql:"type:varchar(100);default:''"`
+	Content    string `json:"content,omitempty" sql:"type:varchar(10000);default:''"`
+	Brief      string `json:"brief" sql:"type:varchar(255);default:''"`
+	Nodeid     int    `json:"nodeid" sql:"default:0"`
+	Reco       int    `json:"reco" sql:"default:0"`
+	Pass       int    `json:"pass" sql:"default:0"`
+	Source     string `json:"source" sql:"type:varchar(100);default:''"`
+	Tags       string `json:"tags" sql:"type:varchar(100);default:''"`
+	Link       string `json:"link" sql:"type:varchar(100);default:''"`
+	Format     string `json:"format" sql:"type:varchar(10);default:'html'"`
+	Markdown   string `json:"markdown,omitempty" sql:"type:text"`
+}
+
+func (r Revision) TableName() string {
+	return "pz_article_revision"
+}
+
+/**
+ * 根据文章生成快照
+ * @method NewRevision
+ * @param  {[type]} article Article [description]
+ */
+func NewRevision(article Article) Revision {
+	return Revision{
+		Articleid: article.ID,
+		Title:     article.Title,
+		Timg:      article.Timg,
+		Content:   article.Content,
+		Brief:     article.Brief,
+		Nodeid:    article.Nodeid,
+		Reco:      article.Reco,
+		Pass:      article.Pass,
+		Source:    article.Source,
+		Tags:      article.Tags,
+		Link:      article.Link,
+		Format:    article.Format,
+		Markdown:  article.Markdown,
+	}
+}
+
+/**
+ * 快照转换成文章，用于回滚
+ * @method Article
+ */
+func (r Revision) Article() Article {
+	return Article{
+		ID:       r.Articleid,
+		Title:    r.Title,
+		Timg:     r.Timg,
+		Content:  r.Content,
+		Brief:    r.Brief,
+		Nodeid:   r.Nodeid,
+		Reco:     r.Reco,
+		Pass:     r.Pass,
+		Source:   r.Source,
+		Tags:     r.Tags,
+		Link:     r.Link,
+		Format:   r.Format,
+		Markdown: r.Markdown,
+	}
+}
+
+/**
+ * 获取revision
+ * @method RevisionGet
+ * @param  {[type]} id int [description]
+ */
+func RevisionGet(id int) Revision {
+	var revision Revision
+	DB.First(&revision, id)
+	return revision
+}
+
+/**
+ * 创建revision，版本号自动加1
+ * @method RevisionCreate
+ * @param  {[type]}   revision Revision [description]
+ */
+func RevisionCreate(revision Revision) error {
+	tx := DB.Begin()
+	var last Revision
+	tx.Set("gorm:query_option", "FOR UPDATE").Where("articleid = ?", revision.Articleid).Order("version desc").Limit(1).Find(&last)
+	revision.Version = last.Version + 1
+	if err := tx.Create(&revision).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+/**
+ * 文章的revision数量
+ * @method RevisionCount
+ * @param  {[type]} articleid int [description]
+ */
+func RevisionCount(articleid int) int {
+	var count int
+	DB.Model(Revision{}).Where("articleid = ?", articleid).Count(&count)
+	return count
+}
+
+/**
+ * 获取文章的revision列表，不包含content
+ * @method RevisionPage
+ * @param  {[type]} articleid int [description]
+ * @param  {[type]} cp        int [description]
+ * @param  {[type]} mp        int [description]
+ */
+func RevisionPage(articleid int, cp int, mp int) ApiJson {
+	var revisions []Revision
+	var count int
+	DB.Table("pz_article_revision").Select("id,articleid,version,uid,username,createtime,title,timg,brief,nodeid,reco,pass,source,tags,link,format").Where("articleid = ?", articleid).Count(&count).Order("version desc").Offset((cp - 1) * mp).Limit(mp).Find(&revisions)
+	return ApiJson{State: true, Msg: revisions, Count: count}
+}
@@ -0,0 +1,142 @@
+package model
+
+import ()
+
+type Role struct {
+	ID          int    `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	Name        string `json:"name" sql:"type:varchar(30);default:''" validate:"required,max=30,min=2"`  //角色标识，如editor
+	Title       string `json:"title" sql:"type:varchar(30);default:''" validate:"required,max=30,min=2"` //角色名称
+	Permissions string `json:"permissions" sql:"type:varchar(1000);default:''" validate:"max=1000"`      //权限列表，逗号隔开，*代表全部权限
+}
+
+type UserRole struct {
+	Uid    int `json:"uid" sql:"default:0"`
+	Roleid int `json:"roleid" sql:"default:0"`
+}
+
+func (r Role) TableName() string {
+	return "pz_role"
+}
+
+func (r UserRole) TableName() string {
+	return "pz_user_role"
+}
+
+/**
+ * 根据role id获取role
+ * @method RoleGet
+ * @param  {[type]} id int [description]
+ */
+func RoleGet(id int) ApiJson {
+	var role Role
+	DB.First(&role, id)
+	return ApiJson{State: true, Msg: role}
+}
+
+/**
+ * 根据id数组获取role
+ * @method RoleList
+ * @param  {[type]} ids []int [description]
+ */
+func RoleList(ids []int) []Role {
+	var roles []Role
+	DB.Where("id in (?) ", ids).Find(&roles)
+	return roles
+}
+
+/**
+ * 获取所有的role
+ * @method RoleAll
+ */
+func RoleAll() ApiJson {
+	var roles []Role
+	DB.Order("id").Find(&roles)
+	return ApiJson{State: true, Msg: roles, Count: len(roles)}
+}
+
+/**
+ * 创建role
+ * @method RoleCreate
+ * @param  {[type]}   role Role [description]
+ */
+func RoleCreate(role Role) ApiJson {
+	err := DB.Save(&role).Error
+	if err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	return ApiJson{State: true, Msg: role.ID}
+}
+
+/**
+ * 更新role
+ * @method RoleUpdate
+ * @param  {[type]}   role Role [description]
+ */
+func RoleUpdate(role Role) ApiJson {
+	err := DB.Model(&role).UpdateColumns(map[string]interface{}{"name": role.Name, "title": role.Title, "permissions": role.Permissions}).Error
+	if err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	return ApiJson{State: true}
+}
+
+/**
+ * 删除role，同时删除用户和role的关联
+ * @method RoleDele
+ * @param  {[type]} ids int[] [description]
+ */
+func RoleDele(ids []int) ApiJson {
+	tx := DB.Begin()
+	if err := tx.Where("roleid in (?) ", ids).Delete(UserRole{}).Error; err != nil {
+		tx.Rollback()
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	if err := tx.Where("id in (?) ", ids).Delete(Role{}).Error; err != nil {
+		tx.Rollback()
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	tx.Commit()
+	return ApiJson{State: true}
+}
+
+/**
+ * 获取用户的所有role
+ * @method UserRoleGet
+ * @param  {[type]} uid int [description]
+ */
+func UserRoleGet(uid int) []Role {
+	var roles []Role
+	DB.Raw("select a.* from pz_role as a,pz_user_role as b where a.id = b.roleid and b.uid = ?", uid).Scan(&roles)
+	return roles
+}
+
+/**
+ * 设置用户的role，会覆盖原有的role
+ * @method UserRoleSet
+ * @param  {[type]} uid     int   [description]
+ * @param  {[type]} roleids int[] [description]
+ */
+func UserRoleSet(uid int, roleids []int) ApiJson {
+	tx := DB.Begin()
+	if err := tx.Where("uid = ?", uid).Delete(UserRole{}).Error; err != nil {
+		tx.Rollback()
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	for _, roleid := range roleids {
+		if err := tx.Create(&UserRole{Uid: uid, Roleid: roleid}).Error; err != nil {
+			tx.Rollback()
+			return ApiJson{State: false, Msg: err.Error()}
+		}
+	}
+	tx.Commit()
+	return ApiJson{State: true}
+}
+
+/**
+ * 删除用户的所有role
+ * @method UserRoleDele
+ * @param  {[type]} uids int[] [description]
+ */
+func UserRoleDele(uids []int) error {
+	return DB.Where("uid in (?) ", uids).Delete(UserRole{}).Error
+}
@@ -0,0 +1,331 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+type SearchIndex struct {
+	Term      string `json:"term" sql:"type:varchar(32);default:''"` //词
+	Articleid int    `json:"articleid" sql:"default:0"`              //文章id
+	Weight    int    `json:"weight" sql:"default:0"`                 //加权后的词频
+}
+
+func (s SearchIndex) TableName() string {
+	return "pz_search_index"
+}
+
+/**
+ * 保存文章的索引，会先删除旧的索引
+ * @method SearchIndexSave
+ * @param  {[type]} articleid int            [description]
+ * @param  {[type]} weights   map[string]int 词=>权重
+ */
+func SearchIndexSave(articleid int, weights map[string]int) error {
+	tx := DB.Begin()
+	if err := tx.Where("articleid = ?", articleid).Delete(SearchIndex{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	var values []string
+	var param []interface{}
+	flush := func() error {
+		if len(values) == 0 {
+			return nil
+		}
+		err := tx.Exec("insert into pz_search_index (term, articleid, weight) values "+strings.Join(values, ","), param...).Error
+		values, param = values[:0], param[:0]
+		return err
+	}
+	for term, weight := range weights {
+		values = append(values, "(?,?,?)")
+		param = append(param, term, articleid, weight)
+		if len(values) >= 500 {
+			if err := flush(); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+/**
+ * 删除文章的索引
+ * @method SearchIndexDele
+ * @param  {[type]} ids []int [description]
+ */
+func SearchIndexDele(ids []int) error {
+	return DB.Where("articleid in (?) ", ids).Delete(SearchIndex{}).Error
+}
+
+type SearchScore struct {
+	Articleid int     `json:"articleid"`
+	Score     float64 `json:"score"`
+}
+
+/**
+ * 每个词命中的文章数，用于计算idf
+ * @method SearchIndexDf
+ * @param  {[type]} terms []string [description]
+ */
+func SearchIndexDf(terms []string) map[string]int {
+	df := map[string]int{}
+	rows, err := DB.Raw("select term,count(*) from pz_search_index where term in (?) group by term", terms).Rows()
+	if err != nil {
+		return df
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var term string
+		var num int
+		rows.Scan(&term, &num)
+		df[term] = num
+	}
+	return df
+}
+
+/**
+ * 按相关度分页查找命中的文章，得分和筛选都在数据库中完成
+ * @method SearchIndexMatch
+ * @param  {[type]} idf    map[string]float64 词=>idf，只查找这些词
+ * @param  {[type]} all    bool               是否要求命中所有词
+ * @param  {[type]} nodeid int                节点id，0表示不限
+ * @param  {[type]} pass   int                审核状态，-1表示不限
+ * @param  {[type]} start  int64              创建时间起，0表示不限
+ * @param  {[type]} end    int64              创建时间止，0表示不限
+ * @param  {[type]} public bool               只返回前台可见的文章
+ * @param  {[type]} scope  []string           授权节点的路径，不为nil时授权节点以外只返回前台可见的文章
+ * @param  {[type]} offset int                [description]
+ * @param  {[type]} limit  int                [description]
+ */
+func SearchIndexMatch(idf map[string]float64, all bool, nodeid int, pass int, start int64, end int64, public bool, scope []string, offset int, limit int) ([]SearchScore, int) {
+	var scores []SearchScore
+	if len(idf) == 0 {
+		return scores, 0
+	}
+	var terms []string
+	score := "case i.term"
+	var scoreParam []interface{}
+	for term, value := range idf {
+		terms = append(terms, term)
+		score += " when ? then ?"
+		scoreParam = append(scoreParam, term, value)
+	}
+	score += " else 0 end"
+	where, param := searchWhere(nodeid, pass, start, end, public, scope)
+	from := " from pz_search_index as i,pz_article as a,pz_node as b where i.articleid = a.id and i.term in (?) and " + where + " group by i.articleid"
+	param = append([]interface{}{terms}, param...)
+	if all {
+		from += " having count(*) = ?"
+		param = append(param, len(terms))
+	}
+	var count int
+	DB.Raw("select count(*) from (select i.articleid"+from+") as t", param...).Row().Scan(&count)
+	if count == 0 || offset >= count || limit <= 0 {
+		return scores, count
+	}
+	param = append(append(scoreParam, param...), offset, limit)
+	DB.Raw("select i.articleid,sum(i.weight * ("+score+")) as score"+from+" order by score desc,i.articleid desc limit ?,?", param...).Scan(&scores)
+	return scores, count
+}
+
+/**
+ * 已建立索引的文章数，用于计算idf
+ * @method SearchIndexCount
+ */
+func SearchIndexCount() int {
+	var count int
+	DB.Model(SearchIndex{}).Select("count(distinct articleid)").Row().Scan(&count)
+	return count
+}
+
+/**
+ * 全部文章id，重建索引时使用
+ * @method ArticleIds
+ */
+func ArticleIds() ([]int, error) {
+	var ids []int
+	err := DB.Model(Article{}).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+/**
+ * 按节点、审核状态和时间筛选文章id
+ * @method SearchFilter
+ * @param  {[type]} ids    []int [description]
+ * @param  {[type]} nodeid int   节点id，0表示不限
+ * @param  {[type]} pass   int   审核状态，-1表示不限
+ * @param  {[type]} start  int64 创建时间起，0表示不限
+ * @param  {[type]} end    int64 创建时间止，0表示不限
+ * @param  {[type]} public bool  只返回前台可见的文章
+ */
+func SearchFilter(ids []int, nodeid int, pass int, start int64, end int64, public bool) map[int]bool {
+	where, param := searchWhere(nodeid, pass, start, end, public, nil)
+	where = "a.id in (?) and " + where
+	param = append([]interface{}{ids}, param...)
+	result := map[int]bool{}
+	rows, err := DB.Raw("select a.id from pz_article as a,pz_node as b where "+where, param...).Rows()
+	if err != nil {
+		return result
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		rows.Scan(&id)
+		result[id] = true
+	}
+	return result
+}
+
+/**
+ * 获取文章列表，包含节点名称，按传入的id顺序返回
+ * @method ArticleResultsList
+ * @param  {[type]} ids []int [description]
+ */
+func ArticleResultsList(ids []int) []ArticleResults {
+	var articles []ArticleResults
+	if len(ids) == 0 {
+		return articles
+	}
+	DB.Raw("select a.*,b.`name` as nodename,c.username from pz_article as a left join pz_node as b on a.nodeid = b.id left join pz_user as c on a.uid = c.id where a.id in (?)", ids).Scan(&articles)
+	byId := map[int]ArticleResults{}
+	for _, article := range articles {
+		byId[article.ID] = article
+	}
+	sorted := make([]ArticleResults, 0, len(articles))
+	for _, id := range ids {
+		if article, ok := byId[id]; ok {
+			sorted = append(sorted, article)
+		}
+	}
+	return sorted
+}
+
+type SearchSuggest struct {
+	Word      string `json:"word" sql:"type:varchar(100);default:''"`     //标题或标签
+	Initials  string `json:"initials" sql:"type:varchar(100);default:''"` //拼音首字母
+	Kind      string `json:"kind" sql:"type:varchar(10);default:''"`      //title、tag
+	Articleid int    `json:"articleid" sql:"default:0"`
+}
+
+func (s SearchSuggest) TableName() string {
+	return "pz_search_suggest"
+}
+
+type SearchSuggestResult struct {
+	Word string `json:"word"`
+	Kind string `json:"kind"`
+	Num  int    `json:"num"` //包含该词的文章数
+}
+
+/**
+ * 保存文章的联想词，会先删除旧的
+ * @method SearchSuggestSave
+ * @param  {[type]} articleid int             [description]
+ * @param  {[type]} suggests  []SearchSuggest [description]
+ */
+func SearchSuggestSave(articleid int, suggests []SearchSuggest) error {
+	tx := DB.Begin()
+	if err := tx.Where("articleid = ?", articleid).Delete(SearchSuggest{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, suggest := range suggests {
+		suggest.Articleid = articleid
+		if err := tx.Create(&suggest).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit().Error
+}
+
+/**
+ * 删除文章的联想词
+ * @method SearchSuggestDele
+ * @param  {[type]} ids []int [description]
+ */
+func SearchSuggestDele(ids []int) error {
+	return DB.Where("articleid in (?) ", ids).Delete(SearchSuggest{}).Error
+}
+
+/**
+ * 按前缀或拼音首字母前缀查找联想词，包含的文章越多越靠前
+ * @method SearchSuggestFind
+ * @param  {[type]} prefix string [description]
+ * @param  {[type]} limit  int    [description]
+ * @param  {[type]} public bool   只包含前台可见的文章
+ */
+func SearchSuggestFind(prefix string, limit int, public bool) []SearchSuggestResult {
+	var results []SearchSuggestResult
+	like := likeEscape(prefix) + "%"
+	where := "(s.word like ? or s.initials like ?)"
+	param := []interface{}{like, strings.ToLower(like)}
+	if public {
+		now := time.Now().Unix()
+		where += " and " + ArticlePublicWhere("a.")
+		param = append(param, now, now)
+	}
+	param = append(param, limit)
+	DB.Raw("select s.word,s.kind,count(*) as num from pz_search_suggest as s,pz_article as a where s.articleid = a.id and "+where+" group by s.word,s.kind order by num desc,s.word limit ?", param...).Scan(&results)
+	return results
+}
+
+/**
+ * 获取常用标签，用于拼写纠正
+ * @method SearchSuggestTags
+ * @param  {[type]} limit int [description]
+ */
+func SearchSuggestTags(limit int) []string {
+	var tags []string
+	DB.Model(SearchSuggest{}).Where("kind = ?", "tag").Group("word").Order("count(*) desc").Limit(limit).Pluck("word", &tags)
+	return tags
+}
+
+//////////私有方法
+func likeEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+//按节点、审核状态和时间筛选文章的条件，a为文章表，b为节点表
+//scope不为nil时只有授权节点下的文章不受前台可见的限制
+func searchWhere(nodeid int, pass int, start int64, end int64, public bool, scope []string) (string, []interface{}) {
+	where := "a.nodeid = b.id and a.trash_at = 0"
+	var param []interface{}
+	if nodeid > 0 {
+		where += " and b.nodepath like ?"
+		param = append(param, "%,"+Tools.ParseString(nodeid)+",%")
+	}
+	if pass >= 0 {
+		where += " and a.pass = ?"
+		param = append(param, pass)
+	}
+	if start > 0 {
+		where += " and a.createtime >= ?"
+		param = append(param, start)
+	}
+	if end > 0 {
+		where += " and a.createtime <= ?"
+		param = append(param, end)
+	}
+	if public {
+		now := time.Now().Unix()
+		where += " and " + ArticlePublicWhere("a.")
+		param = append(param, now, now)
+	} else if scope != nil {
+		now := time.Now().Unix()
+		where += " and (" + ArticlePublicWhere("a.")
+		param = append(param, now, now)
+		for _, path := range scope {
+			where += " or b.nodepath like ?"
+			param = append(param, likeEscape(path)+"%")
+		}
+		where += ")"
+	}
+	return where, param
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"time"
+)
+
+type ArticleSlug struct {
+	Slug       string `json:"slug" sql:"type:varchar(100);default:''"` //旧的固定链接
+	Articleid  int    `json:"articleid" sql:"default:0"`
+	Createtime int64  `json:"createtime" sql:"default:0"`
+}
+
+func (s ArticleSlug) TableName() string {
+	return "pz_article_slug"
+}
+
+/**
+ * 根据slug获取文章id，旧的slug返回跳转后的文章id，redirect为true
+ * @method ArticleFindBySlug
+ * @param  {[type]} slug string [description]
+ */
+func ArticleFindBySlug(slug string) (id int, redirect bool) {
+	var article Article
+	DB.Select("id").Where("slug = ?", slug).First(&article)
+	if article.ID > 0 {
+		return article.ID, false
+	}
+	var old ArticleSlug
+	DB.Where("slug = ?", slug).First(&old)
+	return old.Articleid, old.Articleid > 0
+}
+
+/**
+ * slug是否已被其他文章使用，旧的slug不算占用
+ * @method ArticleSlugExist
+ * @param  {[type]} slug      string [description]
+ * @param  {[type]} articleid int    当前文章id，新文章传0
+ */
+func ArticleSlugExist(slug string, articleid int) bool {
+	var count int
+	DB.Model(Article{}).Where("slug = ? and id <> ?", slug, articleid).Count(&count)
+	return count > 0
+}
+
+/**
+ * 修改文章的slug，旧的slug保存到跳转表
+ * @method ArticleSlugSet
+ * @param  {[type]} articleid int    [description]
+ * @param  {[type]} slug      string [description]
+ */
+func ArticleSlugSet(articleid int, slug string) error {
+	tx := DB.Begin()
+	var article Article
+	if err := tx.Select("id,slug").Where("id = ?", articleid).First(&article).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if article.Slug == slug {
+		tx.Rollback()
+		return nil
+	}
+	//新的slug可能是其他文章的旧slug，由当前文章接管
+	if err := tx.Where("slug = ?", slug).Delete(ArticleSlug{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Model(&article).UpdateColumn("slug", slug).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if article.Slug != "" {
+		if err := tx.Exec("replace into pz_article_slug (slug,articleid,createtime) values (?,?,?)", article.Slug, articleid, time.Now().Unix()).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit().Error
+}
+
+/**
+ * 获取文章的旧slug
+ * @method ArticleSlugHistory
+ * @param  {[type]} articleid int [description]
+ */
+func ArticleSlugHistory(articleid int) []ArticleSlug {
+	var slugs []ArticleSlug
+	DB.Where("articleid = ?", articleid).Order("createtime desc").Find(&slugs)
+	return slugs
+}
+
+/**
+ * 删除文章的旧slug，用于删除文章
+ * @method ArticleSlugDele
+ * @param  {[type]} articleids []int [description]
+ */
+func ArticleSlugDele(articleids []int) error {
+	return DB.Where("articleid in (?) ", articleids).Delete(ArticleSlug{}).Error
+}
+
+/**
+ * 没有slug或者还是升级脚本填入的占位slug(article-id)的文章id，用于生成slug
+ * @method ArticleWithoutSlug
+ */
+func ArticleWithoutSlug() []int {
+	var ids []int
+	DB.Model(Article{}).Where("slug = '' or slug is null or slug = concat('article-', id)").Order("id").Pluck("id", &ids)
+	return ids
+}
@@ -0,0 +1,313 @@
+package model
+
+import (
+	"errors"
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+type Tag struct {
+	ID         int    `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	Name       string `json:"name" sql:"type:varchar(30);default:''" validate:"required,max=30"`
+	Count      int    `json:"count" sql:"default:0"` //使用该标签的文章数
+	Createtime int64  `json:"createtime" sql:"default:0"`
+}
+
+type ArticleTag struct {
+	Articleid int `json:"articleid" sql:"default:0"`
+	Tagid     int `json:"tagid" sql:"default:0"`
+	Sort      int `json:"sort" sql:"default:0"` //标签在文章中的顺序
+}
+
+func (t Tag) TableName() string {
+	return "pz_tag"
+}
+
+func (t ArticleTag) TableName() string {
+	return "pz_article_tag"
+}
+
+/**
+ * 根据id获取tag
+ * @method TagGet
+ * @param  {[type]} id int [description]
+ */
+func TagGet(id int) ApiJson {
+	var tag Tag
+	DB.First(&tag, id)
+	return ApiJson{State: true, Msg: tag}
+}
+
+/**
+ * 根据名称获取tag，不存在时ID为0
+ * @method TagFind
+ * @param  {[type]} name string [description]
+ */
+func TagFind(name string) Tag {
+	var tag Tag
+	DB.Where("name = ?", name).First(&tag)
+	return tag
+}
+
+/**
+ * 根据id数组获取tag
+ * @method TagList
+ * @param  {[type]} ids []int [description]
+ */
+func TagList(ids []int) []Tag {
+	var tags []Tag
+	DB.Where("id in (?) ", ids).Find(&tags)
+	return tags
+}
+
+/**
+ * 分页获取tag，按使用次数降序
+ * @method TagPage
+ * @param  {[type]} kw string [description]
+ * @param  {[type]} cp int    [description]
+ * @param  {[type]} mp int    [description]
+ */
+func TagPage(kw string, cp int, mp int) ApiJson {
+	var tags []Tag
+	var count int
+	db := DB.Model(Tag{}).Where("name like ?", "%"+likeEscape(kw)+"%")
+	db.Count(&count)
+	db.Order("count desc,id").Offset((cp - 1) * mp).Limit(mp).Find(&tags)
+	return ApiJson{State: true, Msg: tags, Count: count}
+}
+
+/**
+ * 创建tag
+ * @method TagCreate
+ * @param  {[type]} tag Tag [description]
+ */
+func TagCreate(tag Tag) ApiJson {
+	if TagFind(tag.Name).ID > 0 {
+		return ApiJson{State: false, Msg: "tag is exist"}
+	}
+	tag.ID = 0
+	tag.Count = 0
+	tag.Createtime = time.Now().Unix()
+	if err := DB.Save(&tag).Error; err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	return ApiJson{State: true, Msg: tag.ID}
+}
+
+/**
+ * 重命名tag，同时更新文章的tags字段，返回受影响的文章id
+ * @method TagRename
+ * @param  {[type]} id   int    [description]
+ * @param  {[type]} name string [description]
+ */
+func TagRename(id int, name string) ([]int, error) {
+	if exist := TagFind(name); exist.ID > 0 && exist.ID != id {
+		return nil, errors.New("tag is exist, merge them instead")
+	}
+	tx := DB.Begin()
+	result := tx.Model(Tag{}).Where("id = ?", id).UpdateColumn("name", name)
+	if result.Error != nil {
+		tx.Rollback()
+		return nil, result.Error
+	}
+	articleids := tagArticleIds(tx, []int{id})
+	if err := articleTagsRebuild(tx, articleids); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return articleids, tx.Commit().Error
+}
+
+/**
+ * 删除tag，同时解除文章和tag的关联，返回受影响的文章id
+ * @method TagDele
+ * @param  {[type]} ids []int [description]
+ */
+func TagDele(ids []int) ([]int, error) {
+	tx := DB.Begin()
+	articleids := tagArticleIds(tx, ids)
+	if err := tx.Where("tagid in (?) ", ids).Delete(ArticleTag{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Where("id in (?) ", ids).Delete(Tag{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := articleTagsRebuild(tx, articleids); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return articleids, tx.Commit().Error
+}
+
+/**
+ * 把多个tag合并到目标tag，原tag会被删除，返回受影响的文章id
+ * @method TagMerge
+ * @param  {[type]} from []int 被合并的tag id
+ * @param  {[type]} to   int   目标tag id
+ */
+func TagMerge(from []int, to int) ([]int, error) {
+	tx := DB.Begin()
+	var target Tag
+	if tx.First(&target, to).RecordNotFound() {
+		tx.Rollback()
+		return nil, errors.New("tag is no exist")
+	}
+	articleids := tagArticleIds(tx, from)
+	//文章已经有目标tag时忽略
+	if err := tx.Exec("insert ignore into pz_article_tag (articleid,tagid,sort) select articleid,?,sort from pz_article_tag where tagid in (?)", to, from).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Where("tagid in (?) ", from).Delete(ArticleTag{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Where("id in (?) ", from).Delete(Tag{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tagRecount(tx, []int{to}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := articleTagsRebuild(tx, articleids); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return articleids, tx.Commit().Error
+}
+
+/**
+ * 设置文章的tag，不存在的tag会自动创建，同时更新文章的tags字段
+ * @method ArticleTagSet
+ * @param  {[type]} articleid int      [description]
+ * @param  {[type]} names     []string 按顺序排列的tag名称
+ */
+func ArticleTagSet(articleid int, names []string) error {
+	tx := DB.Begin()
+	var old []int
+	tx.Model(ArticleTag{}).Where("articleid = ?", articleid).Pluck("tagid", &old)
+	if err := tx.Where("articleid = ?", articleid).Delete(ArticleTag{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	tagids := old
+	for i, name := range names {
+		//并发创建同名tag时依靠唯一索引去重
+		if err := tx.Exec("insert ignore into pz_tag (name,count,createtime) values (?,0,?)", name, time.Now().Unix()).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		var tag Tag
+		if err := tx.Where("name = ?", name).First(&tag).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Exec("insert ignore into pz_article_tag (articleid,tagid,sort) values (?,?,?)", articleid, tag.ID, i).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		tagids = append(tagids, tag.ID)
+	}
+	if err := tagRecount(tx, tagids); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := articleTagsRebuild(tx, []int{articleid}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+/**
+ * 删除文章和tag的关联，用于删除文章
+ * @method ArticleTagDele
+ * @param  {[type]} articleids []int [description]
+ */
+func ArticleTagDele(articleids []int) error {
+	tx := DB.Begin()
+	var tagids []int
+	tx.Model(ArticleTag{}).Where("articleid in (?) ", articleids).Pluck("distinct tagid", &tagids)
+	if err := tx.Where("articleid in (?) ", articleids).Delete(ArticleTag{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tagRecount(tx, tagids); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+/**
+ * 获取使用某个tag的文章
+ * @method TagArticles
+ * @param  {[type]} tagid  int  [description]
+ * @param  {[type]} cp     int  [description]
+ * @param  {[type]} mp     int  [description]
+ * @param  {[type]} public bool 前台读取，只返回已发布的文章
+ */
+func TagArticles(tagid int, cp int, mp int, public bool) ApiJson {
+	var articles []ArticleResults
+	var count int
+	where := "t.tagid = ? and t.articleid = a.id and a.trash_at = 0"
+	param := []interface{}{tagid}
+	if public {
+		now := time.Now().Unix()
+		where += " and " + ArticlePublicWhere("a.")
+		param = append(param, now, now)
+	}
+	DB.Raw("select count(*) from pz_article_tag as t,pz_article as a where "+where, param...).Row().Scan(&count)
+	DB.Raw("select a.*,b.`name` as nodename,c.username from pz_article_tag as t,pz_article as a left join pz_node as b on a.nodeid = b.id left join pz_user as c on a.uid = c.id where "+where+" order by a.id desc limit ? offset ?", append(param, mp, (cp-1)*mp)...).Scan(&articles)
+	if public {
+		articles = ArticlesPublic(articles)
+	}
+	return ApiJson{State: true, Msg: articles, Count: count}
+}
+
+/**
+ * 标签云，按文章数降序
+ * @method TagCloud
+ * @param  {[type]} limit  int  [description]
+ * @param  {[type]} public bool 只统计前台可见的文章
+ */
+func TagCloud(limit int, public bool) []Tag {
+	var tags []Tag
+	where := "t.id = at.tagid and at.articleid = a.id and a.trash_at = 0"
+	var param []interface{}
+	if public {
+		now := time.Now().Unix()
+		where += " and " + ArticlePublicWhere("a.")
+		param = append(param, now, now)
+	}
+	param = append(param, limit)
+	DB.Raw("select t.id,t.name,t.createtime,count(*) as count from pz_tag as t,pz_article_tag as at,pz_article as a where "+where+" group by t.id order by count desc,t.id limit ?", param...).Scan(&tags)
+	return tags
+}
+
+//////////私有方法
+//使用这些tag的文章id
+func tagArticleIds(tx *gorm.DB, tagids []int) []int {
+	var ids []int
+	tx.Model(ArticleTag{}).Where("tagid in (?) ", tagids).Pluck("distinct articleid", &ids)
+	return ids
+}
+
+//重新统计tag的文章数
+func tagRecount(tx *gorm.DB, tagids []int) error {
+	if len(tagids) == 0 {
+		return nil
+	}
+	return tx.Exec("update pz_tag set count = (select count(*) from pz_article_tag where tagid = pz_tag.id) where id in (?)", tagids).Error
+}
+
+//按关联表重新生成文章的tags字段，空格隔开
+func articleTagsRebuild(tx *gorm.DB, articleids []int) error {
+	if len(articleids) == 0 {
+		return nil
+	}
+	return tx.Exec("update pz_article set tags = left(ifnull((select group_concat(t.name order by at.sort separator ' ') from pz_article_tag as at,pz_tag as t where at.tagid = t.id and at.articleid = pz_article.id),''),100) where id in (?)", articleids).Error
+}
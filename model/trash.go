@@ -0,0 +1,122 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+type ArticleTrashResults struct {
+	ArticleResults
+	TrashUsername string `json:"trash_username"` //删除人用户名
+}
+
+/**
+ * 把文章移到回收站，已经在回收站的忽略，返回实际移入的文章id
+ * @method ArticleTrash
+ * @param  {[type]} ids []int [description]
+ * @param  {[type]} uid int   删除人id
+ */
+func ArticleTrash(ids []int, uid int) ([]int, error) {
+	var trashed []int
+	tx := DB.Begin()
+	tx.Set("gorm:query_option", "FOR UPDATE").Model(Article{}).Where("id in (?) and trash_at = 0", ids).Pluck("id", &trashed)
+	if len(trashed) == 0 {
+		tx.Rollback()
+		return trashed, nil
+	}
+	if err := tx.Model(Article{}).Where("id in (?) ", trashed).UpdateColumns(map[string]interface{}{"trash_at": time.Now().Unix(), "trash_by": uid}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return trashed, tx.Commit().Error
+}
+
+/**
+ * 从回收站恢复文章，返回实际恢复的文章id
+ * @method ArticleRestore
+ * @param  {[type]} ids []int [description]
+ */
+func ArticleRestore(ids []int) ([]int, error) {
+	var restored []int
+	tx := DB.Begin()
+	tx.Set("gorm:query_option", "FOR UPDATE").Model(Article{}).Where("id in (?) and trash_at > 0", ids).Pluck("id", &restored)
+	if len(restored) == 0 {
+		tx.Rollback()
+		return restored, nil
+	}
+	if err := tx.Model(Article{}).Where("id in (?) ", restored).UpdateColumns(map[string]interface{}{"trash_at": 0, "trash_by": 0}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return restored, tx.Commit().Error
+}
+
+/**
+ * 彻底删除回收站中的文章，同时删除文章的评论和历史版本，返回实际删除的文章id
+ * @method ArticlePurge
+ * @param  {[type]} ids []int [description]
+ */
+func ArticlePurge(ids []int) ([]int, error) {
+	var purged []int
+	tx := DB.Begin()
+	tx.Set("gorm:query_option", "FOR UPDATE").Model(Article{}).Where("id in (?) and trash_at > 0", ids).Pluck("id", &purged)
+	if len(purged) == 0 {
+		tx.Rollback()
+		return purged, nil
+	}
+	if err := tx.Where("articleid in (?) ", purged).Delete(Comment{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Where("articleid in (?) ", purged).Delete(Revision{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Where("id in (?) ", purged).Delete(Article{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return purged, tx.Commit().Error
+}
+
+/**
+ * 在回收站中超过保留时间的文章id
+ * @method ArticleTrashDue
+ * @param  {[type]} before int64 移入回收站的时间早于这个时间
+ * @param  {[type]} limit  int   [description]
+ */
+func ArticleTrashDue(before int64, limit int) []int {
+	var ids []int
+	DB.Model(Article{}).Where("trash_at > 0 and trash_at <= ?", before).Order("trash_at").Limit(limit).Pluck("id", &ids)
+	return ids
+}
+
+/**
+ * 回收站列表，按删除时间倒序
+ * @method ArticleTrashPage
+ * @param  {[type]} kw      string   [description]
+ * @param  {[type]} cp      int      [description]
+ * @param  {[type]} mp      int      [description]
+ * @param  {[type]} paths   []string 授权节点的路径
+ * @param  {[type]} limited bool     是否只返回授权节点下的文章
+ */
+func ArticleTrashPage(kw string, cp int, mp int, paths []string, limited bool) ApiJson {
+	var articles []ArticleTrashResults
+	var count int
+	where := "a.trash_at > 0 and a.title like ?"
+	param := []interface{}{"%" + likeEscape(kw) + "%"}
+	if limited {
+		if len(paths) == 0 {
+			return ApiJson{State: true, Msg: []ArticleTrashResults{}, Count: 0}
+		}
+		var scope []string
+		for _, path := range paths {
+			scope = append(scope, "nodepath like ?")
+			param = append(param, likeEscape(path)+"%")
+		}
+		where += " and a.nodeid in (select id from pz_node where " + strings.Join(scope, " or ") + ")"
+	}
+	DB.Raw("select count(*) from pz_article as a where "+where, param...).Row().Scan(&count)
+	DB.Raw("select a.*,b.`name` as nodename,c.username,d.username as trash_username from pz_article as a left join pz_node as b on a.nodeid = b.id left join pz_user as c on a.uid = c.id left join pz_user as d on a.trash_by = d.id where "+where+" order by a.trash_at desc limit ? offset ?", append(param, mp, (cp-1)*mp)...).Scan(&articles)
+	return ApiJson{State: true, Msg: articles, Count: count}
+}
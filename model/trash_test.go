@@ -0,0 +1,14 @@
+package model
+
+import "testing"
+
+//没有任何节点授权的用户在查询数据库之前就返回空列表
+func TestArticleTrashPageWithoutNodes(t *testing.T) {
+	for _, paths := range [][]string{nil, {}} {
+		result := ArticleTrashPage("", 1, 20, paths, true)
+		articles, ok := result.Msg.([]ArticleTrashResults)
+		if !result.State || !ok || len(articles) != 0 || result.Count != 0 {
+			t.Errorf("ArticleTrashPage() with paths %v = %+v, want an empty page", paths, result)
+		}
+	}
+}
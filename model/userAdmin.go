@@ -4,12 +4,15 @@ import (
 )
 
 type UserAdmin struct {
-	ID       int    `json:"id" gorm:"primary_key;AUTO_INCREMENT" `
-	Username string `json:"username" sql:"type:varchar(30);default:''" validate:"required,max=30,min=4"`
-	Nickname string `json:"nickname" sql:"type:varchar(30);default:''" validate:"required,max=30,min=2"`
-	Password string `json:"password" sql:"size:100;default:''" validate:"omitempty,max=25,min=6"`
-	State    int    `json:"state" sql:"default:0" validate:"gte=-1,lte=3"`
-	Salt     string `json:"salt"`
+	ID           int    `json:"id" gorm:"primary_key;AUTO_INCREMENT" `
+	Username     string `json:"username" sql:"type:varchar(30);default:''" validate:"required,max=30,min=4"`
+	Nickname     string `json:"nickname" sql:"type:varchar(30);default:''" validate:"required,max=30,min=2"`
+	Password     string `json:"password" sql:"size:100;default:''" validate:"omitempty,max=25,min=6"`
+	State        int    `json:"state" sql:"default:0" validate:"gte=-1,lte=3"`
+	Salt         string `json:"salt"`
+	Totp         int    `json:"totp" sql:"default:0"`                    //是否开启了两步验证
+	TotpSecret   string `json:"-" sql:"type:varchar(64);default:''"`   //TOTP密钥
+	TotpRecovery string `json:"-" sql:"type:varchar(1000);default:''"` //恢复码的sha256，逗号隔开
 }
 
 // func init() {
@@ -28,10 +31,32 @@ func (u UserAdmin) TableName() string {
  */
 func UserAdminGet(id int) ApiJson {
 	var user UserAdmin
-	DB.Select("id,username,nickname,state").First(&user, id)
+	DB.Select("id,username,nickname,state,totp").First(&user, id)
 	return ApiJson{State: true, Msg: user}
 }
 
+/**
+ * 根据user id获取 user结构体，不包含密码
+ * @method UserAdminFind
+ * @param  {[type]} id int [description]
+ */
+func UserAdminFind(id int) UserAdmin {
+	var user UserAdmin
+	DB.Select("id,username,nickname,state,totp").First(&user, id)
+	return user
+}
+
+/**
+ * 根据id数组获取user，不包含密码
+ * @method UserAdminList
+ * @param  {[type]} ids []int [description]
+ */
+func UserAdminList(ids []int) []UserAdmin {
+	var users []UserAdmin
+	DB.Select("id,username,nickname,state,totp").Where("id in (?) ", ids).Find(&users)
+	return users
+}
+
 /**
  * 校验用户登录
  * @method UserAdminCheckLogin
@@ -50,28 +75,89 @@ func UserAdminCheckLogin(username string) UserAdmin {
  * @param  {[type]}   user UserAdmin [description]
  */
 func UserAdminUpdate(user UserAdmin) ApiJson {
-	var err error
-	if user.Password == "" {
-		err = DB.Model(&user).UpdateColumns(map[string]interface{}{"Username": user.Username, "nickname": user.Nickname}).Error
-	} else {
-		var salt = Tools.GetRandomString(10)
-		err = DB.Model(&user).UpdateColumns(map[string]interface{}{"Username": user.Username, "Nickname": user.Nickname, "Password": Tools.MD5(user.Password + salt), "salt": salt}).Error
+	if user.ID < 1 { //没有主键时gorm不加where条件，会更新所有用户
+		return ApiJson{State: false, Msg: "id is error"}
 	}
-	if err != nil {
-		return ApiJson{State: false, Msg: err}
+	columns := map[string]interface{}{"username": user.Username, "nickname": user.Nickname}
+	if user.Password != "" {
+		hash, err := Tools.Password().Hash(user.Password)
+		if err != nil {
+			return ApiJson{State: false, Msg: err.Error()}
+		}
+		columns["password"] = hash
+		columns["salt"] = ""
+	}
+	db := DB.Model(UserAdmin{}).Where("id = ?", user.ID).UpdateColumns(columns)
+	if db.Error != nil {
+		return ApiJson{State: false, Msg: db.Error.Error()}
+	}
+	if db.RowsAffected == 0 && UserAdminFind(user.ID).ID == 0 { //内容没有变化时影响行数也是0
+		return ApiJson{State: false, Msg: "user is no exist"}
 	}
 	return ApiJson{State: true}
 }
 
+/**
+ * 更新密码hash，用于登录时升级旧的md5密码
+ * @method UserAdminSetPassword
+ * @param  {[type]}   id   int    [description]
+ * @param  {[type]}   hash string [description]
+ */
+func UserAdminSetPassword(id int, hash string) error {
+	return DB.Model(UserAdmin{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{"password": hash, "salt": ""}).Error
+}
+
+/**
+ * 设置两步验证信息，secret为空表示关闭
+ * @method UserAdminSetTotp
+ * @param  {[type]}   id       int    [description]
+ * @param  {[type]}   secret   string [description]
+ * @param  {[type]}   recovery string [description]
+ */
+func UserAdminSetTotp(id int, secret string, recovery string) error {
+	totp := 0
+	if secret != "" {
+		totp = 1
+	}
+	return DB.Model(UserAdmin{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{"totp": totp, "totp_secret": secret, "totp_recovery": recovery}).Error
+}
+
+/**
+ * 消耗恢复码，只有恢复码没有被其他请求修改过才更新，返回是否更新成功
+ * @method UserAdminUseTotpRecovery
+ * @param  {[type]}   id       int    [description]
+ * @param  {[type]}   old      string 读取到的恢复码
+ * @param  {[type]}   recovery string 去掉已使用的恢复码后的值
+ */
+func UserAdminUseTotpRecovery(id int, old string, recovery string) bool {
+	db := DB.Model(UserAdmin{}).Where("id = ? and totp_recovery = ?", id, old).UpdateColumns(map[string]interface{}{"totp_recovery": recovery})
+	return db.Error == nil && db.RowsAffected == 1
+}
+
+/**
+ * 根据id获取完整的user，包含两步验证信息，仅内部使用
+ * @method UserAdminFull
+ * @param  {[type]} id int [description]
+ */
+func UserAdminFull(id int) UserAdmin {
+	var user UserAdmin
+	DB.First(&user, id)
+	return user
+}
+
 /**
  * 创建user
  * @method UserAdminCreate
  * @param  {[type]}   user UserAdmin [description]
  */
 func UserAdminCreate(user UserAdmin) ApiJson {
-	var salt = Tools.GetRandomString(10)
-	user.Password = Tools.MD5(user.Password + salt)
-	user.Salt = salt
+	hash, err := Tools.Password().Hash(user.Password)
+	if err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	user.Password = hash
+	user.Salt = "" //salt已包含在hash中
+	user.Totp, user.TotpSecret, user.TotpRecovery = 0, "", ""
 	DB.Save(&user)
 	return ApiJson{State: true, Msg: user.ID}
 }
@@ -0,0 +1,75 @@
+package model
+
+type UserNode struct {
+	Uid    int `json:"uid" sql:"default:0"`
+	Nodeid int `json:"nodeid" sql:"default:0"`
+}
+
+func (u UserNode) TableName() string {
+	return "pz_user_node"
+}
+
+/**
+ * 获取授权给用户的节点
+ * @method UserNodeGet
+ * @param  {[type]} uid int [description]
+ */
+func UserNodeGet(uid int) []Node {
+	var nodes []Node
+	DB.Raw("select a.* from pz_node as a,pz_user_node as b where a.id = b.nodeid and b.uid = ? order by a.nodepath", uid).Scan(&nodes)
+	return nodes
+}
+
+/**
+ * 用户是否不限节点，读取pz_user.node_all，用户不存在时返回false
+ * @method UserNodeAll
+ * @param  {[type]} uid int [description]
+ */
+func UserNodeAll(uid int) bool {
+	var all int
+	DB.Table("pz_user").Where("id = ?", uid).Select("node_all").Row().Scan(&all)
+	return all == 1
+}
+
+/**
+ * 设置用户的节点授权，会覆盖原有的授权，all为true时不限节点并清空授权
+ * @method UserNodeSet
+ * @param  {[type]} uid     int   [description]
+ * @param  {[type]} nodeids []int [description]
+ * @param  {[type]} all     bool  是否不限节点
+ */
+func UserNodeSet(uid int, nodeids []int, all bool) ApiJson {
+	nodeAll := 0
+	if all {
+		nodeAll = 1
+		nodeids = nil
+	}
+	tx := DB.Begin()
+	if err := tx.Table("pz_user").Where("id = ?", uid).UpdateColumn("node_all", nodeAll).Error; err != nil {
+		tx.Rollback()
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	if err := tx.Where("uid = ?", uid).Delete(UserNode{}).Error; err != nil {
+		tx.Rollback()
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	for _, nodeid := range nodeids {
+		if err := tx.Create(&UserNode{Uid: uid, Nodeid: nodeid}).Error; err != nil {
+			tx.Rollback()
+			return ApiJson{State: false, Msg: err.Error()}
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return ApiJson{State: false, Msg: err.Error()}
+	}
+	return ApiJson{State: true}
+}
+
+/**
+ * 删除用户的所有节点授权
+ * @method UserNodeDele
+ * @param  {[type]} uids []int [description]
+ */
+func UserNodeDele(uids []int) error {
+	return DB.Where("uid in (?) ", uids).Delete(UserNode{}).Error
+}
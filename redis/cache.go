@@ -0,0 +1,144 @@
+package redis
+
+import (
+	"encoding/json"
+	"github.com/garyburd/redigo/redis"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cachePrefix     = "cache:"      //缓存数据
+	cacheLockPrefix = "cache:lock:" //重建缓存的锁
+	cacheTagPrefix  = "cache:tag:"  //标签包含的缓存key
+	cacheStatsKey   = "cache:stats" //命中统计，hash 名称:hit/miss=>次数
+	cacheLockEx     = 10            //重建缓存的最长时间，单位秒
+	cacheWait       = 20            //等待其他请求重建缓存的次数
+	cacheWaitTime   = 50 * time.Millisecond
+	cacheTagEx      = 86400 //标签集合的过期时间，单位秒，需要大于缓存的过期时间
+)
+
+type CacheStat struct {
+	Hit   int     `json:"hit"`
+	Miss  int     `json:"miss"`
+	Ratio float64 `json:"ratio"` //命中率
+}
+
+/**
+ * 读取缓存，不存在时调用load获取数据并写入缓存，结果以json解析到out
+ * 同一个key同时只有一个请求执行load，其他请求等待缓存写入，等待超时后直接调用load
+ * @method func
+ * @param  {[type]} name string         统计名称，如article
+ * @param  {[type]} key  string         缓存key
+ * @param  {[type]} ttl  int            过期时间，单位秒
+ * @param  {[type]} tags []string       标签，用于批量清除
+ * @param  {[type]} out  interface{}    结果指针
+ * @param  {[type]} load func() (interface{}, error) 获取数据
+ */
+func (n *Redis) Remember(name string, key string, ttl int, tags []string, out interface{}, load func() (interface{}, error)) error {
+	key = cachePrefix + key
+	if data, err := redis.Bytes(n.Do("GET", key)); err == nil {
+		n.Do("HINCRBY", cacheStatsKey, name+":hit", 1)
+		return json.Unmarshal(data, out)
+	}
+	n.Do("HINCRBY", cacheStatsKey, name+":miss", 1)
+	lock := cacheLockPrefix + key
+	token := strconv.FormatInt(time.Now().UnixNano(), 36)
+	if !n.Lock(lock, token, cacheLockEx) {
+		//其他请求正在重建
+		for i := 0; i < cacheWait; i++ {
+			time.Sleep(cacheWaitTime)
+			if data, err := redis.Bytes(n.Do("GET", key)); err == nil {
+				return json.Unmarshal(data, out)
+			}
+		}
+		return n.load(out, load)
+	}
+	defer n.Unlock(lock, token)
+	value, err := load()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if _, err := n.Do("SET", key, data, "EX", ttl); err == nil {
+		for _, tag := range tags {
+			n.Do("SADD", cacheTagPrefix+tag, key)
+			n.Do("EXPIRE", cacheTagPrefix+tag, cacheTagEx)
+		}
+	}
+	return json.Unmarshal(data, out)
+}
+
+/**
+ * 清除标签下的所有缓存
+ * @method func
+ * @param  {[type]} tags ...string [description]
+ */
+func (n *Redis) Invalidate(tags ...string) {
+	for _, tag := range tags {
+		set := cacheTagPrefix + tag
+		keys, err := n.SMembers(set)
+		if err != nil {
+			continue
+		}
+		args := []interface{}{set}
+		for _, key := range keys {
+			args = append(args, key)
+		}
+		n.Del(args...)
+	}
+}
+
+/**
+ * 缓存命中统计
+ * @method func
+ */
+func (n *Redis) CacheStats() map[string]CacheStat {
+	stats := map[string]CacheStat{}
+	values, err := redis.IntMap(n.Do("HGETALL", cacheStatsKey))
+	if err != nil {
+		return stats
+	}
+	for field, count := range values {
+		i := strings.LastIndex(field, ":")
+		if i < 0 {
+			continue
+		}
+		stat := stats[field[:i]]
+		if field[i+1:] == "hit" {
+			stat.Hit = count
+		} else {
+			stat.Miss = count
+		}
+		if total := stat.Hit + stat.Miss; total > 0 {
+			stat.Ratio = float64(stat.Hit) / float64(total)
+		}
+		stats[field[:i]] = stat
+	}
+	return stats
+}
+
+/**
+ * 清空缓存命中统计
+ * @method func
+ */
+func (n *Redis) CacheStatsReset() {
+	n.Del(cacheStatsKey)
+}
+
+//////////私有方法
+func (n *Redis) load(out interface{}, load func() (interface{}, error)) error {
+	value, err := load()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -44,7 +44,7 @@ func setPoll() {
     MaxActive: r.Maxactive,
     IdleTimeout: 180*time.Second,
     Dial: func() (redis.Conn, error) {//建立连接
-				log.Printf(r.Connect)
+				log.Print(r.Connect)
       c, err := redis.Dial("tcp", r.Connect)
       if err != nil {
         panic(err)
@@ -90,3 +90,72 @@ func (n *Redis) GetString(key string) (string, error) {
 	value, err := redis.String(conn.Do("GET", key))
   return value, err
 }
+
+/**
+ * 删除键
+ * @method func
+ * @param  {[type]} n *Redis        [description]
+ * @return {[type]}   [description]
+ */
+func (n *Redis) Del(keys ...interface{}) (int, error) {
+	conn := redisClient.Get()
+	defer conn.Close()
+	return redis.Int(conn.Do("DEL", keys...))
+}
+
+/**
+ * 获取集合的所有成员
+ * @method func
+ * @param  {[type]} n *Redis        [description]
+ * @return {[type]}   [description]
+ */
+func (n *Redis) SMembers(key string) ([]string, error) {
+	conn := redisClient.Get()
+	defer conn.Close()
+	return redis.Strings(conn.Do("SMEMBERS", key))
+}
+
+/**
+ * 获取分布式锁，token用于释放时校验，ex单位是秒
+ * @method func
+ * @param  {[type]} n *Redis        [description]
+ * @return {[type]}   [description]
+ */
+func (n *Redis) Lock(key string, token string, ex int) bool {
+	conn := redisClient.Get()
+	defer conn.Close()
+	_, err := redis.String(conn.Do("SET", key, token, "NX", "EX", ex))
+	return err == nil
+}
+
+var unlockScript = redis.NewScript(1, `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`)
+
+/**
+ * 释放分布式锁，只有持有者才能释放
+ * @method func
+ * @param  {[type]} n *Redis        [description]
+ * @return {[type]}   [description]
+ */
+func (n *Redis) Unlock(key string, token string) {
+	conn := redisClient.Get()
+	defer conn.Close()
+	unlockScript.Do(conn, key, token)
+}
+
+var setGreaterScript = redis.NewScript(1, `local last = tonumber(redis.call("get", KEYS[1]) or "0")
+if tonumber(ARGV[1]) <= last then return 0 end
+redis.call("set", KEYS[1], ARGV[1], "EX", ARGV[2])
+return 1`)
+
+/**
+ * 原子地比较并设置，只有value大于当前值时才写入并返回true，ex单位是秒
+ * @method func
+ * @param  {[type]} n *Redis        [description]
+ * @return {[type]}   [description]
+ */
+func (n *Redis) SetIfGreater(key string, value int64, ex int) bool {
+	conn := redisClient.Get()
+	defer conn.Close()
+	ok, err := redis.Int(setGreaterScript.Do(conn, key, value, ex))
+	return err == nil && ok == 1
+}
@@ -0,0 +1,22 @@
+package tools
+
+/**
+ * 计算第fails次失败后的等待时间，从base开始每次翻倍，不超过max
+ * @method BackoffDelay
+ * @param  {[type]} fails int 失败次数
+ * @param  {[type]} base  int 第一次失败的等待时间
+ * @param  {[type]} max   int 最长等待时间
+ */
+func (t *Tools) BackoffDelay(fails int, base int, max int) int {
+	if fails <= 0 || base <= 0 {
+		return 0
+	}
+	delay := base
+	for i := 1; i < fails && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
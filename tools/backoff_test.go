@@ -0,0 +1,30 @@
+package tools
+
+import "testing"
+
+func TestBackoffDelay(t *testing.T) {
+	tool := New()
+	tests := []struct {
+		fails int
+		base  int
+		max   int
+		want  int
+	}{
+		{0, 500, 8000, 0},
+		{-1, 500, 8000, 0},
+		{3, 0, 8000, 0},
+		{1, 500, 8000, 500},
+		{2, 500, 8000, 1000},
+		{3, 500, 8000, 2000},
+		{5, 500, 8000, 8000},
+		{6, 500, 8000, 8000},
+		{100, 500, 8000, 8000},
+		{3, 500, 1500, 1500},
+		{1, 500, 300, 300},
+	}
+	for _, tt := range tests {
+		if got := tool.BackoffDelay(tt.fails, tt.base, tt.max); got != tt.want {
+			t.Errorf("BackoffDelay(%d, %d, %d) = %d, want %d", tt.fails, tt.base, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package tools
+
+import "reflect"
+
+/**
+ * 把src中指定名称的字段复制到dst，dst必须是结构体指针，src是同类型的结构体
+ * 如回滚文章版本时保留文章当前的节点和定时设置
+ * @method KeepFields
+ * @param  {[type]} dst   interface{} [description]
+ * @param  {[type]} src   interface{} [description]
+ * @param  {[type]} names ...string   字段名
+ */
+func (t *Tools) KeepFields(dst interface{}, src interface{}, names ...string) {
+	d := reflect.ValueOf(dst).Elem()
+	s := reflect.ValueOf(src)
+	for _, name := range names {
+		to := d.FieldByName(name)
+		from := s.FieldByName(name)
+		if to.IsValid() && from.IsValid() && to.CanSet() && from.Type() == to.Type() {
+			to.Set(from)
+		}
+	}
+}
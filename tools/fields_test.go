@@ -0,0 +1,32 @@
+package tools
+
+import "testing"
+
+type keepArticle struct {
+	ID        int
+	Title     string
+	Nodeid    int
+	PublishAt int64
+	ExpireAt  int64
+}
+
+func TestKeepFieldsRestore(t *testing.T) {
+	tool := New()
+	current := keepArticle{ID: 7, Title: "new title", Nodeid: 5, PublishAt: 100, ExpireAt: 200}
+	restored := keepArticle{ID: 7, Title: "old title", Nodeid: 3}
+	tool.KeepFields(&restored, current, "Nodeid", "PublishAt", "ExpireAt")
+	want := keepArticle{ID: 7, Title: "old title", Nodeid: 5, PublishAt: 100, ExpireAt: 200}
+	if restored != want {
+		t.Errorf("KeepFields() = %+v, want %+v", restored, want)
+	}
+}
+
+func TestKeepFieldsUnknown(t *testing.T) {
+	tool := New()
+	current := keepArticle{Nodeid: 5}
+	restored := keepArticle{Nodeid: 3}
+	tool.KeepFields(&restored, current, "Missing", "")
+	if restored.Nodeid != 3 {
+		t.Errorf("KeepFields() changed Nodeid to %d for unknown fields", restored.Nodeid)
+	}
+}
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"net"
+	"strings"
+)
+
+/**
+ * 获取客户端ip，只有直接连接的地址是受信任的代理时才使用X-Forwarded-For和X-Real-Ip
+ * X-Forwarded-For从右往左跳过受信任的代理，取第一个不是代理的地址
+ * @method ClientIp
+ * @param  {[type]} remote    string   tcp连接的地址
+ * @param  {[type]} forwarded string   X-Forwarded-For
+ * @param  {[type]} realIp    string   X-Real-Ip
+ * @param  {[type]} proxies   []string 受信任的代理，ip或者cidr
+ */
+func (t *Tools) ClientIp(remote string, forwarded string, realIp string, proxies []string) string {
+	if !ipTrusted(remote, proxies) {
+		return remote
+	}
+	if forwarded != "" {
+		ips := strings.Split(forwarded, ",")
+		for i := len(ips) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(ips[i])
+			if net.ParseIP(ip) == nil {
+				break
+			}
+			if !ipTrusted(ip, proxies) {
+				return ip
+			}
+		}
+	}
+	if ip := strings.TrimSpace(realIp); net.ParseIP(ip) != nil {
+		return ip
+	}
+	return remote
+}
+
+//////////私有方法
+func ipTrusted(ip string, proxies []string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, proxy := range proxies {
+		if strings.Contains(proxy, "/") {
+			if _, cidr, err := net.ParseCIDR(proxy); err == nil && cidr.Contains(addr) {
+				return true
+			}
+		} else if other := net.ParseIP(proxy); other != nil && other.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package tools
+
+import "testing"
+
+func TestClientIp(t *testing.T) {
+	tool := New()
+	proxies := []string{"10.0.0.1", "172.16.0.0/12"}
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded string
+		realIp    string
+		want      string
+	}{
+		{"direct", "1.2.3.4", "", "", "1.2.3.4"},
+		{"untrusted remote ignores headers", "1.2.3.4", "5.6.7.8", "9.9.9.9", "1.2.3.4"},
+		{"trusted proxy", "10.0.0.1", "5.6.7.8", "", "5.6.7.8"},
+		{"trusted cidr", "172.16.3.4", "5.6.7.8", "", "5.6.7.8"},
+		{"spoofed left entry", "10.0.0.1", "6.6.6.6, 5.6.7.8", "", "5.6.7.8"},
+		{"skip proxy chain", "10.0.0.1", "5.6.7.8, 172.20.0.1, 10.0.0.1", "", "5.6.7.8"},
+		{"invalid entry stops", "10.0.0.1", "5.6.7.8, bad", "9.9.9.9", "9.9.9.9"},
+		{"only proxies falls back to real ip", "10.0.0.1", "10.0.0.1", "9.9.9.9", "9.9.9.9"},
+		{"real ip", "10.0.0.1", "", " 9.9.9.9 ", "9.9.9.9"},
+		{"invalid real ip", "10.0.0.1", "", "bad", "10.0.0.1"},
+		{"ipv6", "10.0.0.1", "2001:db8::1", "", "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		if got := tool.ClientIp(tt.remote, tt.forwarded, tt.realIp, proxies); got != tt.want {
+			t.Errorf("%s: ClientIp() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"crypto/subtle"
+	"golang.org/x/crypto/bcrypt"
+	"regexp"
+)
+
+/**
+ * 密码加密接口，方便以后替换成其他算法
+ */
+type PasswordHasher interface {
+	Hash(password string) (string, error)     //生成密码hash
+	Verify(hash string, password string) bool //校验密码
+	NeedsRehash(hash string) bool             //hash参数已过时，需要重新生成
+}
+
+/**
+ * bcrypt实现，salt已包含在hash中
+ */
+type BcryptHasher struct {
+	Cost int
+}
+
+var (
+	hasher    PasswordHasher = BcryptHasher{Cost: bcrypt.DefaultCost}
+	legacyMD5                = regexp.MustCompile(`^[0-9a-f]{32}$`)
+)
+
+func (b BcryptHasher) Hash(password string) (string, error) {
+	h, err := bcrypt.GenerateFromPassword([]byte(password), b.Cost)
+	return string(h), err
+}
+
+func (b BcryptHasher) Verify(hash string, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+func (b BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != b.Cost
+}
+
+/**
+ * 返回当前使用的密码加密实现
+ * @method Password
+ */
+func (t *Tools) Password() PasswordHasher {
+	return hasher
+}
+
+/**
+ * 是否是旧版的md5(password+salt)密码
+ * @method IsLegacyPassword
+ * @param  {[type]} hash string [description]
+ */
+func (t *Tools) IsLegacyPassword(hash string) bool {
+	return legacyMD5.MatchString(hash)
+}
+
+/**
+ * 校验旧版的md5(password+salt)密码
+ * @method VerifyLegacyPassword
+ * @param  {[type]} hash     string [description]
+ * @param  {[type]} password string [description]
+ * @param  {[type]} salt     string [description]
+ */
+func (t *Tools) VerifyLegacyPassword(hash string, password string, salt string) bool {
+	return subtle.ConstantTimeCompare([]byte(t.MD5(password+salt)), []byte(hash)) == 1
+}
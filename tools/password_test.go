@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+)
+
+func TestBcryptHasher(t *testing.T) {
+	b := BcryptHasher{Cost: bcrypt.MinCost}
+	hash, err := b.Hash("123456")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+	}{
+		{"right password", hash, "123456", true},
+		{"wrong password", hash, "1234567", false},
+		{"empty password", hash, "", false},
+		{"not a hash", "123456", "123456", false},
+		{"empty hash", "", "", false},
+	}
+	for _, tt := range tests {
+		if got := b.Verify(tt.hash, tt.password); got != tt.want {
+			t.Errorf("%s: Verify() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	other, _ := b.Hash("123456")
+	if other == hash {
+		t.Errorf("Hash() returned the same hash twice, salt is missing")
+	}
+}
+
+func TestBcryptHasherNeedsRehash(t *testing.T) {
+	b := BcryptHasher{Cost: bcrypt.MinCost}
+	same, _ := b.Hash("123456")
+	higher, _ := BcryptHasher{Cost: bcrypt.MinCost + 1}.Hash("123456")
+	tests := []struct {
+		name string
+		hash string
+		want bool
+	}{
+		{"same cost", same, false},
+		{"other cost", higher, true},
+		{"legacy md5", "e10adc3949ba59abbe56e057f20f883e", true},
+		{"empty", "", true},
+	}
+	for _, tt := range tests {
+		if got := b.NeedsRehash(tt.hash); got != tt.want {
+			t.Errorf("%s: NeedsRehash() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsLegacyPassword(t *testing.T) {
+	tool := New()
+	bcryptHash, _ := BcryptHasher{Cost: bcrypt.MinCost}.Hash("123456")
+	tests := []struct {
+		hash string
+		want bool
+	}{
+		{"e10adc3949ba59abbe56e057f20f883e", true},
+		{"E10ADC3949BA59ABBE56E057F20F883E", false},
+		{"e10adc3949ba59abbe56e057f20f883", false},
+		{"e10adc3949ba59abbe56e057f20f883e0", false},
+		{bcryptHash, false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := tool.IsLegacyPassword(tt.hash); got != tt.want {
+			t.Errorf("IsLegacyPassword(%q) = %v, want %v", tt.hash, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyLegacyPassword(t *testing.T) {
+	tool := New()
+	hash := tool.MD5("123456" + "salt")
+	tests := []struct {
+		name     string
+		password string
+		salt     string
+		want     bool
+	}{
+		{"right password", "123456", "salt", true},
+		{"wrong password", "654321", "salt", false},
+		{"wrong salt", "123456", "pepper", false},
+		{"salt moved", "123456sa", "lt", true},
+		{"empty", "", "", false},
+	}
+	for _, tt := range tests {
+		if got := tool.VerifyLegacyPassword(hash, tt.password, tt.salt); got != tt.want {
+			t.Errorf("%s: VerifyLegacyPassword() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
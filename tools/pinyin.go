@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+/**
+ * 转换为拼音，如"马航MH370残片"用"-"连接返回"ma-hang-mh370-can-pian"
+ * 英文和数字转小写，连续的作为一个词，其他字符作为分隔
+ * @method Pinyin
+ * @param  {[type]} text string [description]
+ * @param  {[type]} sep  string 分隔符
+ */
+func (t *Tools) Pinyin(text string, sep string) string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range text {
+		if py, ok := pinyinOf(r); ok {
+			flush()
+			words = append(words, py)
+		} else if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			word.WriteRune(unicode.ToLower(r))
+		} else {
+			flush()
+		}
+	}
+	flush()
+	return strings.Join(words, sep)
+}
+
+/**
+ * 获取拼音首字母，如"北京2016"返回"bj2016"，英文和数字转小写保留，其他字符忽略
+ * @method PinyinInitials
+ * @param  {[type]} text string [description]
+ */
+func (t *Tools) PinyinInitials(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if py, ok := pinyinOf(r); ok {
+			b.WriteByte(py[0])
+		} else if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+/**
+ * 编辑距离，按字计算，用于纠正拼写错误
+ * @method Levenshtein
+ * @param  {[type]} a string [description]
+ * @param  {[type]} b string [description]
+ */
+func (t *Tools) Levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+//////////私有方法
+var (
+	pinyinOnce sync.Once
+	pinyinMap  map[rune]string
+)
+
+func pinyinOf(r rune) (string, bool) {
+	pinyinOnce.Do(func() {
+		pinyinMap = make(map[rune]string, 21000)
+		for py, chars := range pinyinTable {
+			for _, c := range chars {
+				pinyinMap[c] = py
+			}
+		}
+	})
+	py, ok := pinyinMap[r]
+	return py, ok
+}
+
+func minInt(nums ...int) int {
+	m := nums[0]
+	for _, n := range nums[1:] {
+		if n < m {
+			m = n
+		}
+	}
+	return m
+}
@@ -0,0 +1,71 @@
+package tools
+
+import "testing"
+
+func TestPinyin(t *testing.T) {
+	tool := New()
+	tests := []struct {
+		text string
+		sep  string
+		want string
+	}{
+		{"马航MH370残片", "-", "ma-hang-mh370-can-pian"},
+		{"北京", "", "beijing"},
+		{"Hello, World!", "-", "hello-world"},
+		{"中文 搜索", " ", "zhong wen sou suo"},
+		{"!!!", "-", ""},
+		{"", "-", ""},
+		{"café", "-", "caf"},
+	}
+	for _, tt := range tests {
+		if got := tool.Pinyin(tt.text, tt.sep); got != tt.want {
+			t.Errorf("Pinyin(%q, %q) = %q, want %q", tt.text, tt.sep, got, tt.want)
+		}
+	}
+}
+
+func TestPinyinInitials(t *testing.T) {
+	tool := New()
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"北京2016", "bj2016"},
+		{"中文搜索", "zwss"},
+		{"Go 语言", "goyy"},
+		{"，。！", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := tool.PinyinInitials(tt.text); got != tt.want {
+			t.Errorf("PinyinInitials(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tool := New()
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"same", "same", 0},
+		{"北京", "背景", 2},
+		{"中文搜索", "中文检索", 1},
+		{"ab", "ba", 2},
+	}
+	for _, tt := range tests {
+		if got := tool.Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := tool.Levenshtein(tt.b, tt.a); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package tools
+
+import (
+	"golang.org/x/net/html"
+	"html/template"
+	"strings"
+	"unicode"
+)
+
+const maxTermLen = 32 //单个词的最大字节数，超过的截断
+
+/**
+ * 分词：中文按单字和相邻两字切分，英文和数字按单词切分并转小写，其他字符作为分隔符
+ * 返回的词会重复出现，用于统计词频
+ * @method Segment
+ * @param  {[type]} text string [description]
+ */
+func (t *Tools) Segment(text string) []string {
+	var terms []string
+	var word []rune
+	var han []rune
+	flushWord := func() {
+		if len(word) > 0 {
+			terms = append(terms, truncateTerm(strings.ToLower(string(word))))
+			word = word[:0]
+		}
+	}
+	flushHan := func() {
+		for i := range han {
+			terms = append(terms, string(han[i]))
+			if i+1 < len(han) {
+				terms = append(terms, string(han[i:i+2]))
+			}
+		}
+		han = han[:0]
+	}
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+	return terms
+}
+
+/**
+ * 查询分词：中文多于一个字时只用相邻两字，单个字用单字，结果去重
+ * @method SegmentQuery
+ * @param  {[type]} text string [description]
+ */
+func (t *Tools) SegmentQuery(text string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	for _, term := range t.Segment(text) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	//去掉已经被两字词覆盖的单字
+	var result []string
+	for _, term := range terms {
+		r := []rune(term)
+		if len(r) == 1 && unicode.Is(unicode.Han, r[0]) && coveredByBigram(term, terms) {
+			continue
+		}
+		result = append(result, term)
+	}
+	return result
+}
+
+/**
+ * 去掉html标签，返回纯文本，实体会被解码
+ * @method StripTags
+ * @param  {[type]} s string [description]
+ */
+func (t *Tools) StripTags(s string) string {
+	z := html.NewTokenizer(strings.NewReader(s))
+	var b strings.Builder
+	skip := 0 //script和style里的内容不要
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return strings.TrimSpace(b.String())
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			if tag := string(name); tag == "script" || tag == "style" {
+				skip++
+			}
+			b.WriteString(" ")
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if tag := string(name); (tag == "script" || tag == "style") && skip > 0 {
+				skip--
+			}
+			b.WriteString(" ")
+		case html.SelfClosingTagToken:
+			b.WriteString(" ")
+		case html.TextToken:
+			if skip == 0 {
+				b.Write(z.Text())
+			}
+		}
+	}
+}
+
+/**
+ * 高亮关键词，返回以命中位置为中心、最多length个字的片段，关键词用<em>包起来，其余内容做html转义
+ * @method Highlight
+ * @param  {[type]} text   string   纯文本
+ * @param  {[type]} words  []string 关键词
+ * @param  {[type]} length int      片段长度，0表示不截取
+ */
+func (t *Tools) Highlight(text string, words []string, length int) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	start := 0
+	if length > 0 && len(runes) > length {
+		first := -1
+		for _, w := range words {
+			if i := runeIndex(lower, []rune(strings.ToLower(w))); i >= 0 && (first == -1 || i < first) {
+				first = i
+			}
+		}
+		if first > length/4 {
+			start = first - length/4
+		}
+		if start+length > len(runes) {
+			start = len(runes) - length
+		}
+		runes = runes[start : start+length]
+		lower = lower[start : start+length]
+	}
+	mark := make([]bool, len(runes))
+	for _, w := range words {
+		wr := []rune(strings.ToLower(w))
+		if len(wr) == 0 {
+			continue
+		}
+		for i := 0; i+len(wr) <= len(lower); {
+			j := runeIndex(lower[i:], wr)
+			if j < 0 {
+				break
+			}
+			for k := i + j; k < i+j+len(wr); k++ {
+				mark[k] = true
+			}
+			i += j + len(wr)
+		}
+	}
+	var b strings.Builder
+	for i := 0; i < len(runes); i++ {
+		if !mark[i] {
+			b.WriteString(template.HTMLEscapeString(string(runes[i])))
+			continue
+		}
+		j := i
+		for j < len(runes) && mark[j] {
+			j++
+		}
+		b.WriteString("<em>" + template.HTMLEscapeString(string(runes[i:j])) + "</em>")
+		i = j - 1
+	}
+	return b.String()
+}
+
+//////////私有方法
+func truncateTerm(term string) string {
+	if len(term) <= maxTermLen {
+		return term
+	}
+	r := []rune(term)
+	for len(string(r)) > maxTermLen {
+		r = r[:len(r)-1]
+	}
+	return string(r)
+}
+
+func coveredByBigram(char string, terms []string) bool {
+	for _, term := range terms {
+		if len([]rune(term)) == 2 && strings.Contains(term, char) {
+			return true
+		}
+	}
+	return false
+}
+
+func runeIndex(s []rune, sub []rune) int {
+	if len(sub) == 0 {
+		return -1
+	}
+	for i := 0; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := range sub {
+			if s[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSegment(t *testing.T) {
+	tool := New()
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"Hello World", []string{"hello", "world"}},
+		{"Go语言 CMS", []string{"go", "语", "语言", "言", "cms"}},
+		{"中文搜索", []string{"中", "中文", "文", "文搜", "搜", "搜索", "索"}},
+		{"iris4,gorm-v1", []string{"iris4", "gorm", "v1"}},
+		{"数据，数据", []string{"数", "数据", "据", "数", "数据", "据"}},
+		{strings.Repeat("a", 40), []string{strings.Repeat("a", 32)}},
+	}
+	for _, tt := range tests {
+		if got := tool.Segment(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Segment(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSegmentQuery(t *testing.T) {
+	tool := New()
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"中", []string{"中"}},
+		{"中文搜索", []string{"中文", "文搜", "搜索"}},
+		{"Go go 语言", []string{"go", "语言"}},
+		{"中 文", []string{"中", "文"}},
+	}
+	for _, tt := range tests {
+		if got := tool.SegmentQuery(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SegmentQuery(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestStripTags(t *testing.T) {
+	tool := New()
+	tests := []struct {
+		html string
+		want string
+	}{
+		{"plain", "plain"},
+		{"<p>Hello&amp;<b>world</b></p>", "Hello& world"},
+		{"a<br/>b", "a b"},
+		{"x<script>alert(1)</script>y", "x  y"},
+		{"<style>p{}</style>text", "text"},
+		{"&lt;p&gt;", "<p>"},
+	}
+	for _, tt := range tests {
+		if got := tool.StripTags(tt.html); got != tt.want {
+			t.Errorf("StripTags(%q) = %q, want %q", tt.html, got, tt.want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tool := New()
+	x := strings.Repeat("x", 60)
+	tests := []struct {
+		name   string
+		text   string
+		words  []string
+		length int
+		want   string
+	}{
+		{"escape", "Hello <World>", []string{"world"}, 0, "Hello &lt;<em>World</em>&gt;"},
+		{"no match", "Hello", []string{"go"}, 0, "Hello"},
+		{"every match", "go and go", []string{"go"}, 0, "<em>go</em> and <em>go</em>"},
+		{"adjacent words merge", "abcd", []string{"ab", "cd"}, 0, "<em>abcd</em>"},
+		{"chinese", "中文搜索引擎", []string{"搜索"}, 0, "中文<em>搜索</em>引擎"},
+		{"empty word", "abc", []string{""}, 0, "abc"},
+		{"window around match", x + "key" + x, []string{"key"}, 20, "xxxxx<em>key</em>xxxxxxxxxxxx"},
+		{"window at end", x[:30] + "key", []string{"key"}, 10, "xxxxxxx<em>key</em>"},
+		{"window at start", "key" + x, []string{"key"}, 5, "<em>key</em>xx"},
+		{"short text not cut", "a key", []string{"key"}, 20, "a <em>key</em>"},
+	}
+	for _, tt := range tests {
+		if got := tool.Highlight(tt.text, tt.words, tt.length); got != tt.want {
+			t.Errorf("%s: Highlight() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package tools
+
+import (
+	"golang.org/x/net/html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//摘要取文本时按换行处理的块级标签
+var summaryBlockTags = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "blockquote": true, "tr": true, "td": true, "th": true, "hr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "section": true, "article": true, "figcaption": true,
+}
+
+/**
+ * 统计字数，中日韩文字每个字算一个，英文和数字按单词算
+ * @method WordCount
+ * @param  {[type]} text string 纯文本
+ */
+func (t *Tools) WordCount(text string) (cjk int, words int) {
+	inWord := false
+	for _, r := range text {
+		switch {
+		case isCjk(r):
+			cjk++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				words++
+			}
+			inWord = true
+		case r == '\'' || r == '-' || r == '_':
+			//单词中间的连接符不拆分
+		default:
+			inWord = false
+		}
+	}
+	return
+}
+
+/**
+ * 抽取式摘要：按句子中高频词的权重打分，取分数最高的几句，按原文顺序拼成不超过length个字的摘要
+ * @method Summary
+ * @param  {[type]} text   string 纯文本
+ * @param  {[type]} length int    摘要的最大字数
+ */
+func (t *Tools) Summary(text string, length int) string {
+	sentences := splitSentences(text)
+	if len(sentences) == 0 || length <= 0 {
+		return ""
+	}
+	//词频只统计两个字以上的词，单字太常见
+	freq := map[string]int{}
+	for _, term := range t.Segment(text) {
+		if utf8.RuneCountInString(term) > 1 {
+			freq[term]++
+		}
+	}
+	scores := make([]float64, len(sentences))
+	for i, s := range sentences {
+		seen := map[string]bool{}
+		for _, term := range t.Segment(s) {
+			if freq[term] > 1 && !seen[term] {
+				seen[term] = true
+				scores[i] += float64(freq[term])
+			}
+		}
+		scores[i] /= math.Sqrt(float64(utf8.RuneCountInString(s)))
+		if i == 0 { //第一句通常是导语
+			scores[i] *= 1.5
+		}
+	}
+	order := make([]int, len(sentences))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+	picked := map[int]bool{}
+	total := 0
+	for _, i := range order {
+		n := utf8.RuneCountInString(sentences[i])
+		if scores[i] > 0 && total+n <= length { //不含高频词的句子通常是碎片，不要
+			picked[i] = true
+			total += n
+		}
+	}
+	if len(picked) == 0 { //没有合适的句子时取最重要的一句，超过长度时截断
+		return truncateText(sentences[order[0]], length)
+	}
+	var b strings.Builder
+	for i, s := range sentences {
+		if picked[i] {
+			b.WriteString(s)
+		}
+	}
+	return b.String()
+}
+
+/**
+ * 把html转成用于生成摘要的纯文本：块级标签换行以便分句，代码块、script和style里的内容不要
+ * @method SummaryText
+ * @param  {[type]} s string html
+ */
+func (t *Tools) SummaryText(s string) string {
+	z := html.NewTokenizer(strings.NewReader(s))
+	var b strings.Builder
+	skip := 0
+	for {
+		switch token := z.Next(); token {
+		case html.ErrorToken:
+			return strings.TrimSpace(b.String())
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if tag == "pre" || tag == "script" || tag == "style" {
+				if token == html.StartTagToken {
+					skip++
+				} else if token == html.EndTagToken && skip > 0 {
+					skip--
+				}
+			}
+			if summaryBlockTags[tag] || tag == "pre" {
+				b.WriteString("\n")
+			} else {
+				b.WriteString(" ")
+			}
+		case html.TextToken:
+			if skip == 0 {
+				b.Write(z.Text())
+			}
+		}
+	}
+}
+
+//////////私有方法
+//截断到length个字以内，优先在空白或标点处截断，避免截断英文单词，结尾加省略号
+func truncateText(s string, length int) string {
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+	if length <= 1 {
+		return "…"
+	}
+	cut := length - 1
+	for i := cut; i > cut/2; i-- {
+		if r := runes[i]; unicode.IsSpace(r) || unicode.IsPunct(r) || isCjk(r) {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
+
+func isCjk(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+//按中英文的句末标点和换行分句，标点和后面的引号、括号保留在句子末尾，多余的空白合并成一个空格
+//英文句号只有后面是空白时才算句末，避免拆开小数和网址
+func splitSentences(text string) []string {
+	var sentences []string
+	var b strings.Builder
+	flush := func() {
+		if s := strings.Join(strings.Fields(b.String()), " "); s != "" {
+			sentences = append(sentences, s)
+		}
+		b.Reset()
+	}
+	end, dot := false, false
+	for _, r := range text {
+		switch {
+		case r == '\n' || r == '\r':
+			flush()
+			end, dot = false, false
+			continue
+		case strings.ContainsRune("”’」』）)\"'", r):
+		case strings.ContainsRune("。！？；!?;…", r):
+			end, dot = true, false
+		case end || (dot && unicode.IsSpace(r)):
+			flush()
+			end, dot = false, false
+		default:
+			dot = false
+		}
+		b.WriteRune(r)
+		if r == '.' {
+			dot = true
+		}
+	}
+	flush()
+	return sentences
+}
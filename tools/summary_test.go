@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWordCount(t *testing.T) {
+	tool := New()
+	tests := []struct {
+		text  string
+		cjk   int
+		words int
+	}{
+		{"", 0, 0},
+		{"中文abc 123", 2, 2},
+		{"don't stop-me now_ok", 0, 3},
+		{"a,b", 0, 2},
+		{"日本語とテキスト", 8, 0},
+		{"한국어 word", 3, 1},
+		{"，。！", 0, 0},
+	}
+	for _, tt := range tests {
+		cjk, words := tool.WordCount(tt.text)
+		if cjk != tt.cjk || words != tt.words {
+			t.Errorf("WordCount(%q) = %d, %d, want %d, %d", tt.text, cjk, words, tt.cjk, tt.words)
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	tool := New()
+	text := "搜索引擎很重要。今天天气不错。搜索引擎需要分词。"
+	tests := []struct {
+		name   string
+		text   string
+		length int
+		want   string
+	}{
+		{"empty", "", 100, ""},
+		{"zero length", text, 0, ""},
+		{"keep order and drop fragments", text, 100, "搜索引擎很重要。搜索引擎需要分词。"},
+		{"best sentence within length", text, 10, "搜索引擎很重要。"},
+		{"truncate best sentence", text, 5, "搜索引擎…"},
+		{"no frequent terms", "Hello world.", 100, "Hello world."},
+		{"truncate at word boundary", "hello world foo", 8, "hello…"},
+	}
+	for _, tt := range tests {
+		if got := tool.Summary(tt.text, tt.length); got != tt.want {
+			t.Errorf("%s: Summary() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSummaryText(t *testing.T) {
+	tool := New()
+	tests := []struct {
+		html string
+		want string
+	}{
+		{"<p>第一段</p><p>第二段</p>", "第一段\n\n第二段"},
+		{"a<b>b</b>c", "a b c"},
+		{"a<br/>b", "a\nb"},
+		{"<pre>code()</pre>text", "text"},
+		{"<script>x()</script>y", "y"},
+		{"<style>p{}</style>y", "y"},
+		{"&lt;tag&gt; &amp;", "<tag> &"},
+	}
+	for _, tt := range tests {
+		if got := tool.SummaryText(tt.html); got != tt.want {
+			t.Errorf("SummaryText(%q) = %q, want %q", tt.html, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		s      string
+		length int
+		want   string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello world foo", 8, "hello…"},
+		{"hello, world", 8, "hello…"},
+		{"abcdefghij", 5, "abcd…"},
+		{"中文搜索引擎", 4, "中文搜…"},
+		{"abc", 1, "…"},
+		{"abc", 0, "…"},
+	}
+	for _, tt := range tests {
+		if got := truncateText(tt.s, tt.length); got != tt.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.s, tt.length, got, tt.want)
+		}
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"第一句。第二句！", []string{"第一句。", "第二句！"}},
+		{"你好？」他说。", []string{"你好？」", "他说。"}},
+		{"He said \"hi.\" Then left.", []string{"He said \"hi.\"", "Then left."}},
+		{"Pi is 3.14 ok.", []string{"Pi is 3.14 ok."}},
+		{"Visit example.com now", []string{"Visit example.com now"}},
+		{"a\n\nb", []string{"a", "b"}},
+		{"  spaced   out  ", []string{"spaced out"}},
+		{"等等……然后", []string{"等等……", "然后"}},
+	}
+	for _, tt := range tests {
+		if got := splitSentences(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitSentences(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"crypto/hmac"
+	"strings"
+)
+
+/**
+ * 给raw加上hmac签名，返回 raw.签名
+ * @method TokenSign
+ * @param  {[type]} raw    string [description]
+ * @param  {[type]} secret string [description]
+ */
+func (t *Tools) TokenSign(raw string, secret string) string {
+	return raw + "." + t.HmacSha256(raw, secret)
+}
+
+/**
+ * 校验 uid.随机串.签名 格式的token，签名正确时返回uid
+ * @method TokenVerify
+ * @param  {[type]} token  string [description]
+ * @param  {[type]} secret string [description]
+ */
+func (t *Tools) TokenVerify(token string, secret string) (string, bool) {
+	arr := strings.Split(token, ".")
+	if len(arr) != 3 || arr[0] == "" || arr[1] == "" {
+		return "", false
+	}
+	sign := t.HmacSha256(arr[0]+"."+arr[1], secret)
+	if !hmac.Equal([]byte(sign), []byte(arr[2])) {
+		return "", false
+	}
+	return arr[0], true
+}
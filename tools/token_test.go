@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenVerify(t *testing.T) {
+	tool := New()
+	secret := "secret"
+	token := tool.TokenSign("12.abcdef", secret)
+	arr := strings.Split(token, ".")
+	tests := []struct {
+		name  string
+		token string
+		uid   string
+		ok    bool
+	}{
+		{"valid", token, "12", true},
+		{"wrong secret", tool.TokenSign("12.abcdef", "other"), "", false},
+		{"uid changed", "13.abcdef." + arr[2], "", false},
+		{"random changed", "12.abcdeg." + arr[2], "", false},
+		{"sign changed", "12.abcdef." + strings.Repeat("0", len(arr[2])), "", false},
+		{"sign missing", "12.abcdef", "", false},
+		{"extra part", token + ".x", "", false},
+		{"empty uid", tool.TokenSign(".abcdef", secret), "", false},
+		{"empty", "", "", false},
+	}
+	for _, tt := range tests {
+		uid, ok := tool.TokenVerify(tt.token, secret)
+		if uid != tt.uid || ok != tt.ok {
+			t.Errorf("%s: TokenVerify() = %q, %v, want %q, %v", tt.name, uid, ok, tt.uid, tt.ok)
+		}
+	}
+}
+
+func TestTokenSign(t *testing.T) {
+	tool := New()
+	a := tool.TokenSign("1.aa", "secret")
+	if a != tool.TokenSign("1.aa", "secret") {
+		t.Errorf("TokenSign() is not deterministic")
+	}
+	if !strings.HasPrefix(a, "1.aa.") || len(a) != len("1.aa.")+64 {
+		t.Errorf("TokenSign() = %q, want 1.aa.<sha256 hex>", a)
+	}
+	if a == tool.TokenSign("1.aa", "other") {
+		t.Errorf("TokenSign() ignores the secret")
+	}
+}
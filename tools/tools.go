@@ -1,13 +1,17 @@
 package tools
 
 import (
+	"crypto/hmac"
 	"crypto/md5"
+	crand "crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
-	"math/rand"
+	"math/big"
 	"reflect"
 	"strconv"
 	"strings"
@@ -48,6 +52,21 @@ func (t *Tools) ParseInt(b string, defInt int) int {
 	}
 }
 
+/**
+ * 逗号隔开的id字符串转换成int数组，忽略不合法的id
+ * @method ParseIds
+ * @param  {[type]} ids string        [description]
+ */
+func (t *Tools) ParseIds(ids string) []int {
+	var idsInt []int
+	for _, id := range strings.Split(ids, ",") {
+		if i := t.ParseInt(strings.TrimSpace(id), 0); i > 0 {
+			idsInt = append(idsInt, i)
+		}
+	}
+	return idsInt
+}
+
 /**
  * int转换string
  * @method parseInt
@@ -80,6 +99,41 @@ func (t *Tools) MD5(data string) string {
 	return fmt.Sprintf("%x", _m.Sum(nil))
 }
 
+/**
+ * hmac-sha256 签名，返回16进制字符串
+ * @method HmacSha256
+ * @param  {[type]} data string [description]
+ * @param  {[type]} key  string [description]
+ */
+func (t *Tools) HmacSha256(data string, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	io.WriteString(mac, data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+/**
+ * sha256 摘要，返回16进制字符串
+ * @method Sha256
+ * @param  {[type]} data string [description]
+ */
+func (t *Tools) Sha256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+/**
+ * 生成n个字节的安全随机数，返回16进制字符串
+ * @method RandomHex
+ * @param  {[type]} n int [description]
+ */
+func (t *Tools) RandomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := crand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
 /**
  * sha1 加密
  * @method MD5
@@ -122,12 +176,17 @@ func (t *Tools) StructToMap(obj interface{}) map[string]interface{} {
 	return data
 }
 
-//生成随机字符串
+//生成随机字符串，使用crypto/rand，每个字符等概率
 func (t *Tools) GetRandomString(n int) string {
 	const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ~!@#$%^&*()+[]{}/<>;:=.,?"
+	max := big.NewInt(int64(len(letterBytes)))
 	b := make([]byte, n)
 	for i := range b {
-		b[i] = letterBytes[rand.Int63()%int64(len(letterBytes))]
+		r, err := crand.Int(crand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		b[i] = letterBytes[r.Int64()]
 	}
 	return string(b)
 }
@@ -185,5 +244,5 @@ func (t *Tools) Base64Decode(str string) string {
  * @return {[type]}   [description]
  */
 func (t *Tools) Logs(s string) {
-	log.Printf(s)
+	log.Print(s)
 }
@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	totpPeriod = 30 //时间步长，单位秒
+	totpDigits = 6  //验证码位数
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+/**
+ * 生成TOTP密钥(base32编码，160位)
+ * @method TotpSecret
+ */
+func (t *Tools) TotpSecret() string {
+	b := make([]byte, 20)
+	if _, err := crand.Read(b); err != nil {
+		panic(err)
+	}
+	return totpEncoding.EncodeToString(b)
+}
+
+/**
+ * 生成otpauth uri，前端可以用它生成二维码给身份验证器扫描
+ * @method TotpURI
+ * @param  {[type]} issuer  string [description]
+ * @param  {[type]} account string [description]
+ * @param  {[type]} secret  string [description]
+ */
+func (t *Tools) TotpURI(issuer string, account string, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprintf("%d", totpDigits))
+	v.Set("period", fmt.Sprintf("%d", totpPeriod))
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+account) + "?" + v.Encode()
+}
+
+/**
+ * 计算某个时间步的验证码(RFC 6238)
+ * @method TotpCode
+ * @param  {[type]} secret  string [description]
+ * @param  {[type]} counter int64  时间步，unix时间/30
+ */
+func (t *Tools) TotpCode(secret string, counter int64) string {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return ""
+	}
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(buf)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	code := (int(sum[offset])&0x7f)<<24 | int(sum[offset+1])<<16 | int(sum[offset+2])<<8 | int(sum[offset+3])
+	return fmt.Sprintf("%0*d", totpDigits, code%1000000)
+}
+
+/**
+ * 校验验证码，允许前后skew个时间步的误差，成功时返回匹配的时间步，用于防重放
+ * @method TotpVerify
+ * @param  {[type]} secret string [description]
+ * @param  {[type]} code   string [description]
+ * @param  {[type]} skew   int    [description]
+ */
+func (t *Tools) TotpVerify(secret string, code string, skew int) (int64, bool) {
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	now := time.Now().Unix() / totpPeriod
+	for i := -skew; i <= skew; i++ {
+		if hmac.Equal([]byte(t.TotpCode(secret, now+int64(i))), []byte(code)) {
+			return now + int64(i), true
+		}
+	}
+	return 0, false
+}
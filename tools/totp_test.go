@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+//RFC 6238 附录B的sha1密钥"12345678901234567890"
+const totpTestSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTotpCode(t *testing.T) {
+	tool := New()
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		if got := tool.TotpCode(totpTestSecret, tt.unix/totpPeriod); got != tt.want {
+			t.Errorf("TotpCode(%d) = %q, want %q", tt.unix, got, tt.want)
+		}
+	}
+	if got := tool.TotpCode(strings.ToLower(totpTestSecret)+"==", 1); got != tool.TotpCode(totpTestSecret, 1) {
+		t.Errorf("TotpCode() should accept lower case and padded secrets")
+	}
+	if got := tool.TotpCode("not base32!", 1); got != "" {
+		t.Errorf("TotpCode() with invalid secret = %q, want empty", got)
+	}
+}
+
+func TestTotpVerify(t *testing.T) {
+	tool := New()
+	tests := []struct {
+		name   string
+		offset int64
+		code   func(string) string
+		skew   int
+		ok     bool
+	}{
+		{"current step", 0, nil, 1, true},
+		{"previous step", -1, nil, 1, true},
+		{"next step", 1, nil, 1, true},
+		{"outside skew", -2, nil, 1, false},
+		{"no skew", -1, nil, 0, false},
+		{"too short", 0, func(c string) string { return c[1:] }, 1, false},
+		{"too long", 0, func(c string) string { return c + "0" }, 1, false},
+		{"wrong code", 0, func(c string) string { return wrongTotp(c) }, 1, false},
+	}
+	for _, tt := range tests {
+		//跨过时间步边界时重新执行，避免偶发失败
+		for retry := 0; retry < 3; retry++ {
+			now := time.Now().Unix() / totpPeriod
+			code := tool.TotpCode(totpTestSecret, now+tt.offset)
+			if tt.code != nil {
+				code = tt.code(code)
+			}
+			step, ok := tool.TotpVerify(totpTestSecret, code, tt.skew)
+			if time.Now().Unix()/totpPeriod != now {
+				continue
+			}
+			if ok != tt.ok {
+				t.Errorf("%s: TotpVerify() ok = %v, want %v", tt.name, ok, tt.ok)
+			}
+			//返回匹配的时间步，调用方据此拒绝重放
+			if ok && step != now+tt.offset {
+				t.Errorf("%s: TotpVerify() step = %d, want %d", tt.name, step, now+tt.offset)
+			}
+			break
+		}
+	}
+}
+
+func TestTotpSecret(t *testing.T) {
+	tool := New()
+	a, b := tool.TotpSecret(), tool.TotpSecret()
+	if len(a) != 32 || a == b {
+		t.Errorf("TotpSecret() = %q, %q, want two different 32 char secrets", a, b)
+	}
+	if tool.TotpCode(a, 1) == "" {
+		t.Errorf("TotpSecret() is not valid base32")
+	}
+}
+
+func wrongTotp(code string) string {
+	b := []byte(code)
+	b[0] = '0' + (b[0]-'0'+1)%10
+	return string(b)
+}
@@ -0,0 +1,116 @@
+/*
+已有数据库的升级脚本，新安装直接导入pizzaCms.sql即可
+按顺序执行，已经执行过的部分跳过
+新增的表(pz_role、pz_user_role、pz_article_revision、pz_search_index、pz_search_suggest、pz_tag、pz_article_tag、pz_article_slug、pz_article_view_day、pz_article_view_flush、pz_user_node、pz_menu、pz_menu_item等)直接执行pizzaCms.sql中对应的CREATE TABLE
+*/
+
+-- ----------------------------
+-- 角色和权限，建表后执行，没有角色时所有需要权限的接口都会返回permission denied
+-- 超级管理员角色分配给受保护的管理员(config.toml中rbac.protected，默认为1)，修改了protected时同步修改下面的uid
+-- ----------------------------
+INSERT IGNORE INTO `pz_role` (`id`, `name`, `title`, `permissions`) VALUES ('1', 'superadmin', '超级管理员', '*');
+INSERT IGNORE INTO `pz_role` (`id`, `name`, `title`, `permissions`) VALUES ('2', 'editor', '编辑', 'article.edit');
+INSERT IGNORE INTO `pz_role` (`id`, `name`, `title`, `permissions`) VALUES ('3', 'reviewer', '审核员', 'article.edit,article.pass');
+INSERT IGNORE INTO `pz_user_role` (`uid`, `roleid`) SELECT 1, `id` FROM `pz_role` WHERE `name` = 'superadmin';
+
+-- ----------------------------
+-- 两步验证
+-- ----------------------------
+ALTER TABLE `pz_user`
+  ADD COLUMN `totp` int(11) NOT NULL DEFAULT '0' COMMENT '是否开启两步验证',
+  ADD COLUMN `totp_secret` varchar(64) NOT NULL DEFAULT '' COMMENT 'TOTP密钥',
+  ADD COLUMN `totp_recovery` varchar(1000) NOT NULL DEFAULT '' COMMENT '恢复码sha256';
+
+-- ----------------------------
+-- 定时发布和自动下线
+-- ----------------------------
+ALTER TABLE `pz_article`
+  ADD COLUMN `publish_at` int(11) NOT NULL DEFAULT '0' COMMENT '定时发布时间',
+  ADD COLUMN `expire_at` int(11) NOT NULL DEFAULT '0' COMMENT '自动下线时间',
+  ADD KEY `publish_at` (`publish_at`),
+  ADD KEY `expire_at` (`expire_at`);
+
+-- ----------------------------
+-- 编辑流程，已审核的文章视为已发布
+-- ----------------------------
+ALTER TABLE `pz_article`
+  ADD COLUMN `status` varchar(20) NOT NULL DEFAULT 'draft' COMMENT '编辑流程状态',
+  ADD COLUMN `reviewer` int(11) NOT NULL DEFAULT '0' COMMENT '审核人id',
+  ADD COLUMN `review_note` varchar(255) NOT NULL DEFAULT '' COMMENT '审核意见',
+  ADD KEY `status` (`status`,`reviewer`);
+UPDATE `pz_article` SET `status` = IF(`pass` = 1, 'published', 'draft');
+
+-- ----------------------------
+-- 全文搜索和搜索联想，建表后运行 -cmd=reindex 建立已有文章的索引
+-- ----------------------------
+
+-- ----------------------------
+-- 标签，建表后运行 -cmd=tags 把文章的tags字段拆分到pz_tag和pz_article_tag，之后tags字段由程序维护
+-- ----------------------------
+
+-- ----------------------------
+-- 固定链接，已有文章先填入唯一的占位slug(article-id)以便加唯一索引，升级后运行 -cmd=slugs 把占位slug替换为标题拼音
+-- ----------------------------
+ALTER TABLE `pz_article`
+  ADD COLUMN `slug` varchar(100) NOT NULL DEFAULT '' COMMENT '固定链接';
+UPDATE `pz_article` SET `slug` = CONCAT('article-', `id`) WHERE `slug` = '';
+ALTER TABLE `pz_article`
+  ADD UNIQUE KEY `slug` (`slug`);
+
+-- ----------------------------
+-- html过滤，升级后运行 -cmd=sanitize 按白名单过滤已有的文章和评论内容，有变化的文章会同时更新搜索索引
+-- ----------------------------
+
+-- ----------------------------
+-- markdown
+-- ----------------------------
+ALTER TABLE `pz_article`
+  ADD COLUMN `format` varchar(10) NOT NULL DEFAULT 'html' COMMENT '内容格式',
+  ADD COLUMN `markdown` text COMMENT 'markdown原文';
+ALTER TABLE `pz_article_revision`
+  ADD COLUMN `format` varchar(10) NOT NULL DEFAULT 'html',
+  ADD COLUMN `markdown` text;
+
+-- ----------------------------
+-- 字数和阅读时间，加字段后运行 -cmd=summary 计算已有文章的字数和阅读时间，并给空摘要生成摘要
+-- ----------------------------
+ALTER TABLE `pz_article`
+  ADD COLUMN `words` int(11) NOT NULL DEFAULT '0' COMMENT '字数',
+  ADD COLUMN `readtime` int(11) NOT NULL DEFAULT '0' COMMENT '预计阅读时间，单位分钟';
+
+-- ----------------------------
+-- 回收站，删除文章改为移到回收站
+-- ----------------------------
+ALTER TABLE `pz_article`
+  ADD COLUMN `trash_at` int(11) NOT NULL DEFAULT '0' COMMENT '移到回收站的时间',
+  ADD COLUMN `trash_by` int(11) NOT NULL DEFAULT '0' COMMENT '删除人id',
+  ADD KEY `trash_at` (`trash_at`);
+ALTER TABLE `pz_comment`
+  ADD KEY `articleid` (`articleid`);
+
+-- ----------------------------
+-- 节点授权，node_all标记用户是否不限节点，已有授权记录的用户改为只能管理授权的节点
+-- ----------------------------
+ALTER TABLE `pz_user`
+  ADD COLUMN `node_all` tinyint(1) NOT NULL DEFAULT '1' COMMENT '是否不限节点，为0时只能管理pz_user_node中授权的节点';
+UPDATE `pz_user` SET `node_all` = 0 WHERE `id` IN (SELECT `uid` FROM `pz_user_node`);
+
+-- ----------------------------
+-- 节点设置，为空或0的设置继承上级节点，hidden由程序根据visibility计算
+-- ----------------------------
+ALTER TABLE `pz_node`
+  ADD COLUMN `visibility` varchar(10) NOT NULL DEFAULT '' COMMENT '可见性public/hidden，为空继承上级节点',
+  ADD COLUMN `hidden` tinyint(1) NOT NULL DEFAULT '0' COMMENT '继承后是否隐藏，由程序维护',
+  ADD COLUMN `comment` varchar(10) NOT NULL DEFAULT '' COMMENT '评论策略enabled/moderated/disabled，为空继承',
+  ADD COLUMN `sort` varchar(10) NOT NULL DEFAULT '' COMMENT '文章默认排序，为空继承',
+  ADD COLUMN `pagesize` int(11) NOT NULL DEFAULT '0' COMMENT '每页文章数，0继承',
+  ADD COLUMN `seo_title` varchar(100) NOT NULL DEFAULT '' COMMENT 'SEO标题',
+  ADD COLUMN `seo_keywords` varchar(255) NOT NULL DEFAULT '' COMMENT 'SEO关键字，为空继承',
+  ADD KEY `hidden` (`hidden`);
+ALTER TABLE `pz_comment`
+  ADD COLUMN `pass` tinyint(1) NOT NULL DEFAULT '1' COMMENT '审核状态：0待审核，1通过，2不通过';
+
+-- ----------------------------
+-- 评论数，只统计审核通过的评论，之后由程序维护
+-- ----------------------------
+UPDATE `pz_article` AS a SET a.`comment` = (SELECT COUNT(*) FROM `pz_comment` AS c WHERE c.`articleid` = a.`id` AND c.`pass` = 1);